@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type ctxKey struct{}
+
+// NewContext loggerを保持したcontextを返す
+func NewContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext ctxに保持されたloggerを返す。保持されていない場合はzap.L()(global logger)を返す
+func FromContext(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return zap.L()
+}
+
+// WithFields ctxのloggerにfieldsを追加したcontextを返す。認証後のユーザー情報の追加等に使用する
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(fields...))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,11 +11,20 @@ import (
 	"time"
 
 	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/auth"
 	"github.com/tokane888/test-mcp/services/api/internal/config"
 	"github.com/tokane888/test-mcp/services/api/internal/db"
 	"github.com/tokane888/test-mcp/services/api/internal/handler"
+	"github.com/tokane888/test-mcp/services/api/internal/health"
+	"github.com/tokane888/test-mcp/services/api/internal/infrastructure/memory"
 	"github.com/tokane888/test-mcp/services/api/internal/infrastructure/persistence"
+	"github.com/tokane888/test-mcp/services/api/internal/metrics"
+	"github.com/tokane888/test-mcp/services/api/internal/migration"
+	"github.com/tokane888/test-mcp/services/api/internal/policy"
+	"github.com/tokane888/test-mcp/services/api/internal/ratelimit"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
 	"github.com/tokane888/test-mcp/services/api/internal/router"
+	"github.com/tokane888/test-mcp/services/api/internal/tracing"
 	"github.com/tokane888/test-mcp/services/api/internal/usecase"
 	"go.uber.org/zap"
 )
@@ -23,6 +33,9 @@ import (
 var version = "dev"
 
 func main() {
+	migrate := flag.Bool("migrate", false, "起動時に未適用のDB migrationを適用する")
+	flag.Parse()
+
 	cfg, err := config.LoadConfig(version)
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
@@ -30,30 +43,129 @@ func main() {
 	logger := pkglogger.NewLogger(cfg.Logger)
 	//nolint: errcheck
 	defer logger.Sync()
+	// contextにloggerを保持していない処理でpkglogger.FromContextを使用した場合の出力先
+	zap.ReplaceGlobals(logger)
+
+	// シグナルハンドリングの設定
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// データベース接続
-	database, err := db.Connect(&cfg.DatabaseConfig)
+	// tracing
+	shutdownTracing, err := tracing.Setup(ctx, &cfg.TracingConfig)
 	if err != nil {
-		logger.Fatal("failed to connect to database", zap.Error(err))
+		logger.Fatal("failed to set up tracing", zap.Error(err))
 	}
 	defer func() {
-		if closeErr := database.Close(); closeErr != nil {
-			logger.Error("failed to close database connection", zap.Error(closeErr))
+		// 未送信のspanを送信するため、shutdown用のcontextとは別にtimeoutを設ける
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Error("failed to shut down tracing", zap.Error(err))
 		}
 	}()
 
+	// liveness/readinessのcheck
+	healthRegistry := health.NewRegistry(cfg.HealthCheckTimeout)
+	// Prometheus向けの指標
+	appMetrics := metrics.NewMetrics()
+
 	// Repository層の初期化
-	userRepository := persistence.NewUserRepository(database, logger)
+	var (
+		userRepository               repository.UserRepository
+		passwordResetTokenRepository repository.PasswordResetTokenRepository
+		apiKeyRepository             repository.APIKeyRepository
+		refreshTokenRepository       repository.RefreshTokenRepository
+		auditEventRepository         repository.AuditEventRepository
+		outboxRepository             repository.OutboxRepository
+		webhookRepository            repository.WebhookSubscriptionRepository
+		webhookDeliveryRepository    repository.WebhookDeliveryRepository
+		txManager                    repository.TxManager
+	)
+	switch cfg.StorageBackend {
+	case config.StorageBackendMemory:
+		logger.Warn("using in-memory storage. all data will be lost when the server exits")
+		userRepository = memory.NewUserRepository()
+		passwordResetTokenRepository = memory.NewPasswordResetTokenRepository()
+		apiKeyRepository = memory.NewAPIKeyRepository()
+		refreshTokenRepository = memory.NewRefreshTokenRepository()
+		auditEventRepository = memory.NewAuditEventRepository()
+		outboxRepository = memory.NewOutboxRepository()
+		webhookRepository = memory.NewWebhookSubscriptionRepository()
+		webhookDeliveryRepository = memory.NewWebhookDeliveryRepository()
+		txManager = memory.NewTxManager(
+			userRepository,
+			passwordResetTokenRepository,
+			apiKeyRepository,
+			refreshTokenRepository,
+			auditEventRepository,
+			outboxRepository,
+			webhookRepository,
+		)
+	default:
+		// データベース接続
+		database, err := db.Connect(ctx, &cfg.DatabaseConfig, logger)
+		if err != nil {
+			logger.Fatal("failed to connect to database", zap.Error(err))
+		}
+		defer func() {
+			if closeErr := database.Close(); closeErr != nil {
+				logger.Error("failed to close database connection", zap.Error(closeErr))
+			}
+		}()
+
+		migrator, err := migration.NewMigrator(database, logger)
+		if err != nil {
+			logger.Fatal("failed to load migrations", zap.Error(err))
+		}
+		if *migrate {
+			if err := migrator.Up(ctx); err != nil {
+				logger.Fatal("failed to run migrations", zap.Error(err))
+			}
+		}
+
+		healthRegistry.AddReadinessCheck("database", health.DatabaseCheck(database))
+		healthRegistry.AddReadinessCheck("migration", health.MigrationCheck(migrator))
+		if err := appMetrics.RegisterDB(database, cfg.DatabaseConfig.DBName); err != nil {
+			logger.Fatal("failed to register database metrics", zap.Error(err))
+		}
+
+		userRepository = persistence.NewUserRepository(database, logger)
+		passwordResetTokenRepository = persistence.NewPasswordResetTokenRepository(database, logger)
+		apiKeyRepository = persistence.NewAPIKeyRepository(database, logger)
+		refreshTokenRepository = persistence.NewRefreshTokenRepository(database, logger)
+		auditEventRepository = persistence.NewAuditEventRepository(database, logger)
+		outboxRepository = persistence.NewOutboxRepository(database, logger)
+		webhookRepository = persistence.NewWebhookSubscriptionRepository(database, logger)
+		webhookDeliveryRepository = persistence.NewWebhookDeliveryRepository(database, logger)
+		txManager = persistence.NewTxManager(database, logger)
+	}
+
+	// アクセストークン(JWT)の署名鍵
+	tokenManager, err := auth.NewManager(&cfg.AuthConfig, logger)
+	if err != nil {
+		logger.Fatal("failed to initialize JWT signing keys", zap.Error(err))
+	}
+
 	// UseCase層の初期化
-	userUseCase := usecase.NewUserUseCase(userRepository, logger)
+	authorizer := policy.NewPolicy()
+	userUseCase := usecase.NewUserUseCase(userRepository, refreshTokenRepository, auditEventRepository, outboxRepository, txManager, authorizer, appMetrics, logger)
+	passwordResetUseCase := usecase.NewPasswordResetUseCase(userRepository, passwordResetTokenRepository, refreshTokenRepository, auditEventRepository, txManager, authorizer, logger)
+	authUseCase := usecase.NewAuthUseCase(userRepository, refreshTokenRepository, txManager, tokenManager, cfg.AuthConfig.RefreshTokenTTL, logger)
+	apiKeyUseCase := usecase.NewAPIKeyUseCase(apiKeyRepository, txManager, logger)
+	auditUseCase := usecase.NewAuditUseCase(auditEventRepository, authorizer, logger)
+	webhookUseCase := usecase.NewWebhookUseCase(webhookRepository, webhookDeliveryRepository, txManager, authorizer, logger)
+	if cfg.BootstrapAdminAPIKey != "" {
+		if err := apiKeyUseCase.RegisterBootstrapKey(ctx, cfg.BootstrapAdminAPIKey); err != nil {
+			logger.Fatal("failed to register bootstrap api key", zap.Error(err))
+		}
+	}
 	// Handler層の初期化
-	h := handler.NewHandler(logger, userUseCase)
-	r := router.NewRouter(&cfg.RouterConfig, logger, h)
-	engine := r.Setup()
-
-	// シグナルハンドリングの設定
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
+	h := handler.NewHandler(logger, userUseCase, passwordResetUseCase, apiKeyUseCase, authUseCase, auditUseCase, webhookUseCase, tokenManager, healthRegistry)
+	r := router.NewRouter(&cfg.RouterConfig, logger, h, appMetrics, ratelimit.NewMemoryStore(), apiKeyUseCase, tokenManager)
+	engine, err := r.Setup()
+	if err != nil {
+		logger.Fatal("failed to set up router", zap.Error(err))
+	}
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.RouterConfig.Port),
@@ -68,16 +180,46 @@ func main() {
 		}
 	}()
 
+	// 運用向けのendpointはAPIとは別のportで公開する
+	var adminSrv *http.Server
+	if cfg.AdminPort > 0 {
+		adminMux := http.NewServeMux()
+		adminMux.Handle("/metrics", appMetrics.Handler())
+		adminSrv = &http.Server{
+			Addr:    fmt.Sprintf(":%d", cfg.AdminPort),
+			Handler: adminMux,
+		}
+		go func() {
+			logger.Info("starting admin server", zap.Int("port", cfg.AdminPort))
+			if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Fatal("admin listen error", zap.Error(err))
+			}
+		}()
+	}
+
 	// シグナル待機
 	<-ctx.Done()
 	logger.Info("shutting down server...")
 
+	// readinessを失敗させ、orchestratorが振り分け対象から外すまでの間は処理を継続する
+	healthRegistry.SetShuttingDown()
+	if cfg.ShutdownDrainPeriod > 0 {
+		logger.Info("draining before shutdown", zap.Duration("period", cfg.ShutdownDrainPeriod))
+		time.Sleep(cfg.ShutdownDrainPeriod)
+	}
+
 	// Graceful shutdown with timeout
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout)*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		logger.Error("server forced to shutdown", zap.Error(err))
 	}
+	// API serverの停止までの指標を取得できるよう、admin serverは最後に停止する
+	if adminSrv != nil {
+		if err := adminSrv.Shutdown(shutdownCtx); err != nil {
+			logger.Error("admin server forced to shutdown", zap.Error(err))
+		}
+	}
 
 	logger.Info("server exited")
 }
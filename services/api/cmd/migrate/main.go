@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/config"
+	"github.com/tokane888/test-mcp/services/api/internal/db"
+	"github.com/tokane888/test-mcp/services/api/internal/migration"
+	"go.uber.org/zap"
+)
+
+// アプリのversion。デフォルトは開発版。cloud上ではbuild時に-ldflagsフラグ経由でバージョンを埋め込む
+var version = "dev"
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up          未適用のmigrationを全て適用
+  down [N]    適用済みのmigrationを新しい順にN件取り消す(デフォルト: 1)
+  status      各migrationの適用状況を表示
+  version     適用済みの最新versionを表示
+`
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.LoadConfig(version)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	logger := pkglogger.NewLogger(cfg.Logger)
+	//nolint: errcheck
+	defer logger.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	database, err := db.Connect(ctx, &cfg.DatabaseConfig, logger)
+	if err != nil {
+		logger.Fatal("failed to connect to database", zap.Error(err))
+	}
+	defer func() {
+		if closeErr := database.Close(); closeErr != nil {
+			logger.Error("failed to close database connection", zap.Error(closeErr))
+		}
+	}()
+
+	migrator, err := migration.NewMigrator(database, logger)
+	if err != nil {
+		logger.Fatal("failed to load migrations", zap.Error(err))
+	}
+
+	if err := run(ctx, migrator, flag.Args()); err != nil {
+		logger.Fatal("migration failed", zap.Error(err))
+	}
+}
+
+func run(ctx context.Context, migrator *migration.Migrator, args []string) error {
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps: %q", args[1])
+			}
+			steps = n
+		}
+		return migrator.Down(ctx, steps)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02T15:04:05Z07:00")
+			}
+			fmt.Printf("%04d  %-40s  %s\n", s.Version, s.Name, appliedAt)
+		}
+		return nil
+	case "version":
+		v, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(v)
+		return nil
+	default:
+		flag.Usage()
+		return fmt.Errorf("unknown command: %q", args[0])
+	}
+}
@@ -0,0 +1,58 @@
+// openapi 登録済みのrouteとDTOからOpenAPIドキュメントを生成する。
+// routeとドキュメントの定義(router.endpoints)の一致はinternal/routerのテストで確認する
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tokane888/test-mcp/services/api/internal/handler"
+	"github.com/tokane888/test-mcp/services/api/internal/router"
+	"go.uber.org/zap"
+)
+
+func main() {
+	output := flag.String("o", "", "生成したドキュメントの出力先(デフォルト: 標準出力)")
+	flag.Parse()
+
+	body, err := generate()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(body)
+	} else {
+		err = os.WriteFile(*output, body, 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write document: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate 依存先なしでrouteを登録し、ドキュメントを生成する
+func generate() ([]byte, error) {
+	// routeの登録時のdebugログを出力しない
+	gin.SetMode(gin.ReleaseMode)
+
+	// handler、middlewareは登録するのみで実行しないため依存先は不要
+	r := router.NewRouter(&router.Config{}, zap.NewNop(), &handler.Handler{}, nil, nil, nil, nil)
+	if _, err := r.Setup(); err != nil {
+		return nil, err
+	}
+
+	doc, err := router.OpenAPIDocument()
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OpenAPI document: %w", err)
+	}
+	return append(body, '\n'), nil
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidToken = errors.New("invalid access token")
+
+type Config struct {
+	Issuer          string
+	Audience        string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// 先頭の鍵で署名し、残りは検証のみに使用する。
+	// rotate時は新しい鍵を先頭に追加し、旧鍵で署名したトークンの期限が切れた後に削除する
+	SigningKeys []KeyFile
+	// trueの場合、SigningKeysが空であれば起動ごとに鍵を生成する。複数プロセスでは検証できないためローカル実行向け
+	AllowEphemeralKey bool
+}
+
+// Claims アクセストークンから取り出した情報
+type Claims struct {
+	UserID uuid.UUID
+	// 発行時点のrole。変更はトークンの再発行時に反映される
+	Role      domain.Role
+	ExpiresAt time.Time
+}
+
+type accessTokenClaims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role"`
+}
+
+// Manager アクセストークン(JWT)の発行と検証を行う
+type Manager struct {
+	config *Config
+	active *signingKey
+	keys   map[string]*signingKey
+	jwks   JWKS
+	parser *jwt.Parser
+}
+
+func NewManager(config *Config, logger *zap.Logger) (*Manager, error) {
+	var keys []*signingKey
+	for _, file := range config.SigningKeys {
+		key, err := loadKeyFile(file)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		if !config.AllowEphemeralKey {
+			return nil, errors.New("no JWT signing key configured")
+		}
+		key, err := generateKey()
+		if err != nil {
+			return nil, err
+		}
+		logger.Warn("JWT signing key is not configured; using an ephemeral key")
+		keys = append(keys, key)
+	}
+	if keys[0].private == nil {
+		return nil, fmt.Errorf("active signing key %q must be a private key", keys[0].id)
+	}
+
+	m := &Manager{
+		config: config,
+		active: keys[0],
+		keys:   make(map[string]*signingKey, len(keys)),
+		jwks:   JWKS{Keys: make([]JWK, 0, len(keys))},
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodES256.Alg()}),
+			jwt.WithIssuer(config.Issuer),
+			jwt.WithAudience(config.Audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+		),
+	}
+	for _, key := range keys {
+		m.keys[key.id] = key
+		jwk, err := key.jwk()
+		if err != nil {
+			return nil, err
+		}
+		m.jwks.Keys = append(m.jwks.Keys, jwk)
+	}
+	return m, nil
+}
+
+// IssueAccessToken returns a signed access token and its expiry
+func (m *Manager) IssueAccessToken(userID uuid.UUID, role domain.Role) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.config.AccessTokenTTL)
+	claims := accessTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.config.Issuer,
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{m.config.Audience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+		Role: string(role),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = m.active.id
+	signed, err := token.SignedString(m.active.private)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// VerifyAccessToken 署名、発行者、対象、有効期限を検証する。検証に失敗した場合はErrInvalidTokenを返す
+func (m *Manager) VerifyAccessToken(tokenString string) (*Claims, error) {
+	var claims accessTokenClaims
+	_, err := m.parser.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := m.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key.public, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subject: %w", ErrInvalidToken, err)
+	}
+	role, err := domain.ParseRole(claims.Role)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	return &Claims{
+		UserID:    userID,
+		Role:      role,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+// JWKS 検証に使用する全ての公開鍵
+func (m *Manager) JWKS() JWKS {
+	return m.jwks
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyFile 署名鍵のPEMファイル
+type KeyFile struct {
+	// JWTのheaderのkidとして使用する
+	ID   string
+	Path string
+}
+
+// ParseKeyFiles "kid=path,kid=path"形式の文字列を解析する
+func ParseKeyFiles(s string) ([]KeyFile, error) {
+	var files []KeyFile
+	seen := make(map[string]struct{})
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, path, ok := strings.Cut(entry, "=")
+		id, path = strings.TrimSpace(id), strings.TrimSpace(path)
+		if !ok || id == "" || path == "" {
+			return nil, fmt.Errorf("invalid signing key %q (expected kid=path)", entry)
+		}
+		if _, dup := seen[id]; dup {
+			return nil, fmt.Errorf("duplicate signing key id %q", id)
+		}
+		seen[id] = struct{}{}
+		files = append(files, KeyFile{ID: id, Path: path})
+	}
+	return files, nil
+}
+
+// signingKey 検証のみに使用する鍵はprivateがnil
+type signingKey struct {
+	id      string
+	private *ecdsa.PrivateKey
+	public  *ecdsa.PublicKey
+}
+
+func loadKeyFile(file KeyFile) (*signingKey, error) {
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %q: %w", file.ID, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %q is not PEM encoded", file.ID)
+	}
+
+	var key any
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("signing key %q has unsupported PEM type %q", file.ID, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %q: %w", file.ID, err)
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("signing key %q must be an ECDSA P-256 key", file.ID)
+		}
+		return &signingKey{id: file.ID, private: k, public: &k.PublicKey}, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("signing key %q must be an ECDSA P-256 key", file.ID)
+		}
+		return &signingKey{id: file.ID, public: k}, nil
+	default:
+		return nil, fmt.Errorf("signing key %q must be an ECDSA P-256 key", file.ID)
+	}
+}
+
+// generateKey プロセス内でのみ有効な鍵を生成する。ローカル実行向け
+func generateKey() (*signingKey, error) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return &signingKey{id: "ephemeral", private: private, public: &private.PublicKey}, nil
+}
+
+// JWK RFC 7517のEC公開鍵
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+func (k *signingKey) jwk() (JWK, error) {
+	pub, err := k.public.ECDH()
+	if err != nil {
+		return JWK{}, fmt.Errorf("failed to convert signing key %q: %w", k.id, err)
+	}
+	// 非圧縮形式(0x04 || X || Y)
+	b := pub.Bytes()
+	size := (len(b) - 1) / 2
+	return JWK{
+		KeyType:   "EC",
+		Curve:     "P-256",
+		X:         base64.RawURLEncoding.EncodeToString(b[1 : 1+size]),
+		Y:         base64.RawURLEncoding.EncodeToString(b[1+size:]),
+		KeyID:     k.id,
+		Use:       "sig",
+		Algorithm: "ES256",
+	}, nil
+}
@@ -1,23 +1,51 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/auth"
 	"github.com/tokane888/test-mcp/services/api/internal/db"
+	"github.com/tokane888/test-mcp/services/api/internal/ratelimit"
 	"github.com/tokane888/test-mcp/services/api/internal/router"
+	"github.com/tokane888/test-mcp/services/api/internal/tracing"
 )
 
+// データの保存先
+const (
+	StorageBackendPostgres = "postgres"
+	// テスト、ローカル実行向け。プロセス終了時にデータは失われる
+	StorageBackendMemory = "memory"
+)
+
+// 推測されにくいよう、初期管理者用のAPI Keyに求める最小の長さ
+const minBootstrapAPIKeyLength = 16
+
 // Config 環境変数を読み取り、各struct向けのConfigを保持
 type Config struct {
-	Env             string
-	RouterConfig    router.Config
+	Env            string
+	StorageBackend string
+	// 起動時にadmin scopeで登録するAPI Key。最初の管理者用キーの発行に使用する。空の場合は登録しない
+	BootstrapAdminAPIKey string
+	RouterConfig         router.Config
+	// /metrics等の運用向けendpointを公開するport。0の場合は起動しない
+	AdminPort       int
 	DatabaseConfig  db.Config
+	TracingConfig   tracing.Config
+	AuthConfig      auth.Config
 	Logger          logger.Config
 	ShutdownTimeout int // graceful shutdown timeout in seconds
+	// shutdown開始からreadinessを失敗させたまま新規リクエストを受け付け続ける時間。
+	// orchestratorが振り分け対象から外すまでの猶予
+	ShutdownDrainPeriod time.Duration
+	// /livez, /readyzの各checkの実行時間上限
+	HealthCheckTimeout time.Duration
 }
 
 // LoadConfig loads environment variables into Config
@@ -33,7 +61,7 @@ func LoadConfig(version string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
-	dbPort, err := getIntEnv("DB_PORT", 5432)
+	adminPort, err := getIntEnv("ADMIN_PORT", 9090)
 	if err != nil {
 		return nil, err
 	}
@@ -41,31 +69,219 @@ func LoadConfig(version string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	shutdownDrainPeriod, err := getDurationEnv("SHUTDOWN_DRAIN_PERIOD", 0)
+	if err != nil {
+		return nil, err
+	}
+	healthCheckTimeout, err := getDurationEnv("HEALTH_CHECK_TIMEOUT", 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	dbConfig, err := loadDatabaseConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	trustedProxies := getListEnv("TRUSTED_PROXIES")
+
+	rateLimitConfig, err := loadRateLimitConfig()
+	if err != nil {
+		return nil, err
+	}
+	tracingConfig, err := loadTracingConfig(version)
+	if err != nil {
+		return nil, err
+	}
+	authConfig, err := loadAuthConfig(env)
+	if err != nil {
+		return nil, err
+	}
+
+	bootstrapAdminAPIKey := getEnv("BOOTSTRAP_ADMIN_API_KEY", "")
+	if bootstrapAdminAPIKey != "" && len(bootstrapAdminAPIKey) < minBootstrapAPIKeyLength {
+		return nil, fmt.Errorf("BOOTSTRAP_ADMIN_API_KEY must be at least %d characters", minBootstrapAPIKeyLength)
+	}
+
+	storageBackend := getEnv("STORAGE_BACKEND", StorageBackendPostgres)
+	if storageBackend != StorageBackendPostgres && storageBackend != StorageBackendMemory {
+		return nil, fmt.Errorf("invalid value for environment variable STORAGE_BACKEND: %q (expected %q or %q)",
+			storageBackend, StorageBackendPostgres, StorageBackendMemory)
+	}
 
 	cfg := &Config{
-		Env: env,
+		Env:                  env,
+		StorageBackend:       storageBackend,
+		BootstrapAdminAPIKey: bootstrapAdminAPIKey,
 		RouterConfig: router.Config{
-			Port: port,
-		},
-		DatabaseConfig: db.Config{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     dbPort,
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "api_db"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Port:           port,
+			TrustedProxies: trustedProxies,
+			RateLimit:      *rateLimitConfig,
 		},
+		AdminPort:      adminPort,
+		DatabaseConfig: *dbConfig,
+		TracingConfig:  *tracingConfig,
+		AuthConfig:     *authConfig,
 		Logger: logger.Config{
 			AppName:    getEnv("APP_NAME", ""),
 			AppVersion: version,
 			Level:      getEnv("LOG_LEVEL", "info"),
 			Format:     getEnv("LOG_FORMAT", "local"),
 		},
-		ShutdownTimeout: shutdownTimeout,
+		ShutdownTimeout:     shutdownTimeout,
+		ShutdownDrainPeriod: shutdownDrainPeriod,
+		HealthCheckTimeout:  healthCheckTimeout,
 	}
 	return cfg, nil
 }
 
+func loadDatabaseConfig() (*db.Config, error) {
+	port, err := getIntEnv("DB_PORT", 5432)
+	if err != nil {
+		return nil, err
+	}
+	maxOpenConns, err := getIntEnv("DB_MAX_OPEN_CONNS", 25)
+	if err != nil {
+		return nil, err
+	}
+	maxIdleConns, err := getIntEnv("DB_MAX_IDLE_CONNS", 10)
+	if err != nil {
+		return nil, err
+	}
+	connMaxLifetime, err := getDurationEnv("DB_CONN_MAX_LIFETIME", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	connMaxIdleTime, err := getDurationEnv("DB_CONN_MAX_IDLE_TIME", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	connectTimeout, err := getDurationEnv("DB_CONNECT_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	statementTimeout, err := getDurationEnv("DB_STATEMENT_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	connectRetries, err := getIntEnv("DB_CONNECT_RETRIES", 5)
+	if err != nil {
+		return nil, err
+	}
+	connectRetryInterval, err := getDurationEnv("DB_CONNECT_RETRY_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return &db.Config{
+		Host:                 getEnv("DB_HOST", "localhost"),
+		Port:                 port,
+		User:                 getEnv("DB_USER", "postgres"),
+		Password:             getEnv("DB_PASSWORD", "postgres"),
+		DBName:               getEnv("DB_NAME", "api_db"),
+		SSLMode:              getEnv("DB_SSLMODE", "disable"),
+		MaxOpenConns:         maxOpenConns,
+		MaxIdleConns:         maxIdleConns,
+		ConnMaxLifetime:      connMaxLifetime,
+		ConnMaxIdleTime:      connMaxIdleTime,
+		ConnectTimeout:       connectTimeout,
+		StatementTimeout:     statementTimeout,
+		ConnectRetries:       connectRetries,
+		ConnectRetryInterval: connectRetryInterval,
+	}, nil
+}
+
+func loadRateLimitConfig() (*ratelimit.Config, error) {
+	enabled, err := getBoolEnv("RATE_LIMIT_ENABLED", true)
+	if err != nil {
+		return nil, err
+	}
+	defaultLimit, err := getLimitEnv("RATE_LIMIT_DEFAULT", "300/1m")
+	if err != nil {
+		return nil, err
+	}
+	createUserLimit, err := getLimitEnv("RATE_LIMIT_CREATE_USER", "30/1m")
+	if err != nil {
+		return nil, err
+	}
+	passwordLimit, err := getLimitEnv("RATE_LIMIT_PASSWORD", "5/1m")
+	if err != nil {
+		return nil, err
+	}
+	return &ratelimit.Config{
+		Enabled:    enabled,
+		Default:    defaultLimit,
+		CreateUser: createUserLimit,
+		Password:   passwordLimit,
+	}, nil
+}
+
+func loadTracingConfig(version string) (*tracing.Config, error) {
+	exporter := getEnv("TRACING_EXPORTER", tracing.ExporterNone)
+	switch exporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout, tracing.ExporterFile:
+	default:
+		return nil, fmt.Errorf("invalid value for environment variable TRACING_EXPORTER: %q (expected one of %q, %q, %q, %q)",
+			exporter, tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout, tracing.ExporterFile)
+	}
+	sampleRatio, err := getFloatEnv("TRACING_SAMPLE_RATIO", 1.0)
+	if err != nil {
+		return nil, err
+	}
+	if sampleRatio < 0 || sampleRatio > 1 {
+		return nil, fmt.Errorf("invalid value for environment variable TRACING_SAMPLE_RATIO: %v (expected 0.0-1.0)", sampleRatio)
+	}
+	otlpInsecure, err := getBoolEnv("TRACING_OTLP_INSECURE", false)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := getEnv("APP_NAME", "")
+	if serviceName == "" {
+		serviceName = "api"
+	}
+	return &tracing.Config{
+		Exporter:       exporter,
+		ServiceName:    serviceName,
+		ServiceVersion: version,
+		OTLPEndpoint:   getEnv("TRACING_OTLP_ENDPOINT", "localhost:4318"),
+		OTLPInsecure:   otlpInsecure,
+		FilePath:       getEnv("TRACING_FILE_PATH", "traces.jsonl"),
+		SampleRatio:    sampleRatio,
+	}, nil
+}
+
+func loadAuthConfig(env string) (*auth.Config, error) {
+	accessTokenTTL, err := getDurationEnv("JWT_ACCESS_TOKEN_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	refreshTokenTTL, err := getDurationEnv("JWT_REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if accessTokenTTL <= 0 || refreshTokenTTL <= 0 {
+		return nil, errors.New("JWT_ACCESS_TOKEN_TTL and JWT_REFRESH_TOKEN_TTL must be positive")
+	}
+	signingKeys, err := auth.ParseKeyFiles(getEnv("JWT_SIGNING_KEYS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for environment variable JWT_SIGNING_KEYS: %w", err)
+	}
+	// 複数インスタンスで検証できるよう、ローカル以外では鍵の指定を必須とする
+	allowEphemeralKey := env == "local"
+	if len(signingKeys) == 0 && !allowEphemeralKey {
+		return nil, fmt.Errorf("environment variable JWT_SIGNING_KEYS is required when ENV=%s (expected kid=/path/to/key.pem)", env)
+	}
+
+	return &auth.Config{
+		Issuer:            getEnv("JWT_ISSUER", "test-mcp-api"),
+		Audience:          getEnv("JWT_AUDIENCE", "test-mcp-app"),
+		AccessTokenTTL:    accessTokenTTL,
+		RefreshTokenTTL:   refreshTokenTTL,
+		SigningKeys:       signingKeys,
+		AllowEphemeralKey: allowEphemeralKey,
+	}, nil
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -73,6 +289,17 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getListEnv カンマ区切りの値を返す。未指定、または空の場合はnil
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getIntEnv(key string, fallback int) (int, error) {
 	if s, exists := os.LookupEnv(key); exists {
 		i, err := strconv.Atoi(s)
@@ -83,3 +310,45 @@ func getIntEnv(key string, fallback int) (int, error) {
 	}
 	return fallback, nil
 }
+
+func getDurationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	if s, exists := os.LookupEnv(key); exists {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value for environment variable %s: %q (expected duration such as \"5s\"): %w", key, s, err)
+		}
+		return d, nil
+	}
+	return fallback, nil
+}
+
+func getFloatEnv(key string, fallback float64) (float64, error) {
+	if s, exists := os.LookupEnv(key); exists {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value for environment variable %s: %q (expected number): %w", key, s, err)
+		}
+		return f, nil
+	}
+	return fallback, nil
+}
+
+func getBoolEnv(key string, fallback bool) (bool, error) {
+	if s, exists := os.LookupEnv(key); exists {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return false, fmt.Errorf("invalid value for environment variable %s: %q (expected true or false): %w", key, s, err)
+		}
+		return b, nil
+	}
+	return fallback, nil
+}
+
+func getLimitEnv(key string, fallback string) (ratelimit.Limit, error) {
+	s := getEnv(key, fallback)
+	limit, err := ratelimit.ParseLimit(s)
+	if err != nil {
+		return ratelimit.Limit{}, fmt.Errorf("invalid value for environment variable %s: %w", key, err)
+	}
+	return limit, nil
+}
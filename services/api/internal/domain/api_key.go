@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyScope API Keyに許可する操作の範囲
+type APIKeyScope string
+
+const (
+	ScopeUsersRead  APIKeyScope = "users:read"
+	ScopeUsersWrite APIKeyScope = "users:write"
+	// ScopeAdmin 全ての操作を許可する
+	ScopeAdmin APIKeyScope = "admin"
+)
+
+// 発行するAPI Keyの接頭辞。漏洩時にsecret scanner等で検出しやすくする
+const apiKeyPrefix = "tmk_"
+
+// 一覧等で識別用に表示する平文の先頭の文字数
+const (
+	apiKeyDisplayPrefixLength = 12
+	// 外部で生成したキーはエントロピーが低い可能性があるため、表示する文字数を減らす
+	externalAPIKeyDisplayPrefixLength = 4
+)
+
+var (
+	ErrInvalidAPIKeyScope = errors.New("invalid api key scope")
+	ErrAPIKeyScopeEmpty   = errors.New("api key must have at least one scope")
+	ErrAPIKeyRevoked      = errors.New("api key has been revoked")
+	ErrAPIKeyExpired      = errors.New("api key has expired")
+)
+
+// APIKey 平文のキーは保持せずハッシュのみ保存する
+type APIKey struct {
+	id         uuid.UUID
+	name       string
+	owner      string
+	prefix     string
+	keyHash    string
+	scopes     []APIKeyScope
+	role       Role
+	expiresAt  *time.Time
+	lastUsedAt *time.Time
+	revokedAt  *time.Time
+	createdAt  time.Time
+}
+
+// NewAPIKey creates a new API key and returns it with the plain key to be handed to the client
+func NewAPIKey(name, owner string, scopes []APIKeyScope, role Role, expiresAt *time.Time) (*APIKey, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	plain := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	key, err := NewAPIKeyFromPlain(name, owner, plain, scopes, role, expiresAt)
+	if err != nil {
+		return nil, "", err
+	}
+	return key, plain, nil
+}
+
+// NewAPIKeyFromPlain 指定された平文のキーを登録する。初期管理者用のキー等、外部で生成したキー向け
+func NewAPIKeyFromPlain(name, owner, plain string, scopes []APIKeyScope, role Role, expiresAt *time.Time) (*APIKey, error) {
+	if err := validateScopes(scopes); err != nil {
+		return nil, err
+	}
+	if err := validateAPIKeyRole(role); err != nil {
+		return nil, err
+	}
+	return &APIKey{
+		id:        uuid.New(),
+		name:      name,
+		owner:     owner,
+		prefix:    displayPrefix(plain),
+		keyHash:   HashAPIKey(plain),
+		scopes:    slices.Clone(scopes),
+		role:      role,
+		expiresAt: expiresAt,
+		createdAt: time.Now(),
+	}, nil
+}
+
+// ReconstructAPIKey reconstructs an APIKey entity from persistence
+func ReconstructAPIKey(
+	id uuid.UUID,
+	name string,
+	owner string,
+	prefix string,
+	keyHash string,
+	scopes []APIKeyScope,
+	role Role,
+	expiresAt *time.Time,
+	lastUsedAt *time.Time,
+	revokedAt *time.Time,
+	createdAt time.Time,
+) *APIKey {
+	return &APIKey{
+		id:         id,
+		name:       name,
+		owner:      owner,
+		prefix:     prefix,
+		keyHash:    keyHash,
+		scopes:     slices.Clone(scopes),
+		role:       role,
+		expiresAt:  expiresAt,
+		lastUsedAt: lastUsedAt,
+		revokedAt:  revokedAt,
+		createdAt:  createdAt,
+	}
+}
+
+func displayPrefix(plain string) string {
+	if strings.HasPrefix(plain, apiKeyPrefix) && len(plain) > apiKeyDisplayPrefixLength {
+		return plain[:apiKeyDisplayPrefixLength]
+	}
+	return plain[:min(len(plain), externalAPIKeyDisplayPrefixLength)]
+}
+
+// HashAPIKey 平文のキーから保存・検索用のハッシュを算出。
+// キーは十分なエントロピーを持つため、パスワードと異なりbcrypt等の低速なハッシュは使用しない
+func HashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseAPIKeyScopes 文字列のscopeを検証して変換する
+func ParseAPIKeyScopes(values []string) ([]APIKeyScope, error) {
+	scopes := make([]APIKeyScope, len(values))
+	for i, v := range values {
+		scopes[i] = APIKeyScope(v)
+	}
+	if err := validateScopes(scopes); err != nil {
+		return nil, err
+	}
+	return scopes, nil
+}
+
+func validateScopes(scopes []APIKeyScope) error {
+	if len(scopes) == 0 {
+		return ErrAPIKeyScopeEmpty
+	}
+	for _, s := range scopes {
+		switch s {
+		case ScopeUsersRead, ScopeUsersWrite, ScopeAdmin:
+		default:
+			return fmt.Errorf("%w: %q", ErrInvalidAPIKeyScope, s)
+		}
+	}
+	return nil
+}
+
+// Getters
+func (k *APIKey) ID() uuid.UUID          { return k.id }
+func (k *APIKey) Name() string           { return k.name }
+func (k *APIKey) Owner() string          { return k.owner }
+func (k *APIKey) Prefix() string         { return k.prefix }
+func (k *APIKey) KeyHash() string        { return k.keyHash }
+func (k *APIKey) Scopes() []APIKeyScope  { return slices.Clone(k.scopes) }
+func (k *APIKey) Role() Role             { return k.role }
+func (k *APIKey) ExpiresAt() *time.Time  { return k.expiresAt }
+func (k *APIKey) LastUsedAt() *time.Time { return k.lastUsedAt }
+func (k *APIKey) RevokedAt() *time.Time  { return k.revokedAt }
+func (k *APIKey) CreatedAt() time.Time   { return k.createdAt }
+func (k *APIKey) IsRevoked() bool        { return k.revokedAt != nil }
+
+// Business methods
+
+// Matches 平文のキーがこのキーのものかを実行時間が一定となる比較で確認する
+func (k *APIKey) Matches(plain string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashAPIKey(plain)), []byte(k.keyHash)) == 1
+}
+
+// Validate 認証に使用できる状態かを確認する
+func (k *APIKey) Validate(now time.Time) error {
+	if k.revokedAt != nil && !now.Before(*k.revokedAt) {
+		return ErrAPIKeyRevoked
+	}
+	if k.expiresAt != nil && !now.Before(*k.expiresAt) {
+		return ErrAPIKeyExpired
+	}
+	return nil
+}
+
+// HasScope ScopeAdminを持つ場合は全てのscopeを持つものとして扱う
+func (k *APIKey) HasScope(scope APIKeyScope) bool {
+	return slices.Contains(k.scopes, scope) || slices.Contains(k.scopes, ScopeAdmin)
+}
+
+// Revoke at以降の認証を拒否する。rotate時に猶予期間を設ける場合は未来の時刻を指定する
+func (k *APIKey) Revoke(at time.Time) error {
+	if k.revokedAt != nil && !at.Before(*k.revokedAt) {
+		// 既に同時刻以前に失効済み
+		return ErrAPIKeyRevoked
+	}
+	k.revokedAt = &at
+	return nil
+}
+
+func (k *APIKey) MarkUsed(at time.Time) {
+	k.lastUsedAt = &at
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"maps"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditActorType 操作主体の種類
+type AuditActorType string
+
+const (
+	AuditActorAPIKey AuditActorType = "api_key"
+	AuditActorUser   AuditActorType = "user"
+	// AuditActorSystem 起動時の処理等、認証を経ない操作
+	AuditActorSystem AuditActorType = "system"
+)
+
+// AuditAction 記録対象の操作
+type AuditAction string
+
+const (
+	AuditActionUserCreated         AuditAction = "user.created"
+	AuditActionUserUpdated         AuditAction = "user.updated"
+	AuditActionUserPasswordChanged AuditAction = "user.password_changed"
+	AuditActionUserPasswordReset   AuditAction = "user.password_reset"
+	AuditActionUserDeleted         AuditAction = "user.deleted"
+	AuditActionUserRestored        AuditAction = "user.restored"
+	AuditActionUserHardDeleted     AuditAction = "user.hard_deleted"
+	AuditActionUserRoleChanged     AuditAction = "user.role_changed"
+)
+
+type AuditActor struct {
+	Type AuditActorType
+	// API KeyのID、またはユーザーのID。systemの場合は空
+	ID string
+}
+
+// AuditEvent ユーザーに対する操作の記録。変更されたフィールドの変更前後の値のみ保持する
+type AuditEvent struct {
+	id           uuid.UUID
+	occurredAt   time.Time
+	actor        AuditActor
+	action       AuditAction
+	targetUserID uuid.UUID
+	// 作成時はbefore、物理削除時はafterがnil
+	before    map[string]any
+	after     map[string]any
+	requestID string
+	clientIP  string
+}
+
+// NewAuditEvent before, afterはUserAuditFieldsで取得した値を渡す。変更のないフィールドは除外する
+func NewAuditEvent(
+	actor AuditActor,
+	action AuditAction,
+	targetUserID uuid.UUID,
+	before map[string]any,
+	after map[string]any,
+	requestID string,
+	clientIP string,
+) *AuditEvent {
+	if before != nil && after != nil {
+		before, after = diffAuditFields(before, after)
+	}
+	return &AuditEvent{
+		id: uuid.New(),
+		// cursorでの比較がPostgreSQLの精度と一致するようマイクロ秒単位とする
+		occurredAt:   time.Now().Truncate(time.Microsecond),
+		actor:        actor,
+		action:       action,
+		targetUserID: targetUserID,
+		before:       before,
+		after:        after,
+		requestID:    requestID,
+		clientIP:     clientIP,
+	}
+}
+
+// ReconstructAuditEvent reconstructs an AuditEvent entity from persistence
+func ReconstructAuditEvent(
+	id uuid.UUID,
+	occurredAt time.Time,
+	actor AuditActor,
+	action AuditAction,
+	targetUserID uuid.UUID,
+	before map[string]any,
+	after map[string]any,
+	requestID string,
+	clientIP string,
+) *AuditEvent {
+	return &AuditEvent{
+		id:           id,
+		occurredAt:   occurredAt,
+		actor:        actor,
+		action:       action,
+		targetUserID: targetUserID,
+		before:       before,
+		after:        after,
+		requestID:    requestID,
+		clientIP:     clientIP,
+	}
+}
+
+// UserAuditFields 監査ログに記録するユーザーの値。パスワードハッシュ等の秘匿情報は含めない
+func UserAuditFields(u *User) map[string]any {
+	fields := map[string]any{
+		"email":    u.email,
+		"username": u.username,
+		"role":     string(u.role),
+		"version":  u.version,
+	}
+	if u.deletedAt != nil {
+		fields["deleted_at"] = u.deletedAt.UTC().Format(time.RFC3339Nano)
+	} else {
+		fields["deleted_at"] = nil
+	}
+	return fields
+}
+
+// diffAuditFields 値の異なるフィールドのみを残す
+func diffAuditFields(before, after map[string]any) (map[string]any, map[string]any) {
+	b, a := make(map[string]any), make(map[string]any)
+	keys := maps.Clone(before)
+	maps.Copy(keys, after)
+	for k := range keys {
+		if !reflect.DeepEqual(before[k], after[k]) {
+			b[k] = before[k]
+			a[k] = after[k]
+		}
+	}
+	return b, a
+}
+
+// Getters
+func (e *AuditEvent) ID() uuid.UUID           { return e.id }
+func (e *AuditEvent) OccurredAt() time.Time   { return e.occurredAt }
+func (e *AuditEvent) Actor() AuditActor       { return e.actor }
+func (e *AuditEvent) Action() AuditAction     { return e.action }
+func (e *AuditEvent) TargetUserID() uuid.UUID { return e.targetUserID }
+func (e *AuditEvent) Before() map[string]any  { return maps.Clone(e.before) }
+func (e *AuditEvent) After() map[string]any   { return maps.Clone(e.after) }
+func (e *AuditEvent) RequestID() string       { return e.requestID }
+func (e *AuditEvent) ClientIP() string        { return e.clientIP }
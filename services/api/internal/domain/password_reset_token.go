@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrResetTokenExpired = errors.New("password reset token has expired")
+	ErrResetTokenUsed    = errors.New("password reset token has already been used")
+)
+
+// PasswordResetToken パスワードリセット用の使い捨てトークン。平文のトークンは保持せずハッシュのみ保存する
+type PasswordResetToken struct {
+	id        uuid.UUID
+	userID    uuid.UUID
+	tokenHash string
+	expiresAt time.Time
+	usedAt    *time.Time
+	createdAt time.Time
+}
+
+// NewPasswordResetToken creates a new token and returns it with the plain token to be sent to the user
+func NewPasswordResetToken(userID uuid.UUID, ttl time.Duration) (*PasswordResetToken, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	plain := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now()
+	return &PasswordResetToken{
+		id:        uuid.New(),
+		userID:    userID,
+		tokenHash: HashResetToken(plain),
+		expiresAt: now.Add(ttl),
+		createdAt: now,
+	}, plain, nil
+}
+
+// ReconstructPasswordResetToken reconstructs a PasswordResetToken entity from persistence
+func ReconstructPasswordResetToken(
+	id uuid.UUID,
+	userID uuid.UUID,
+	tokenHash string,
+	expiresAt time.Time,
+	usedAt *time.Time,
+	createdAt time.Time,
+) *PasswordResetToken {
+	return &PasswordResetToken{
+		id:        id,
+		userID:    userID,
+		tokenHash: tokenHash,
+		expiresAt: expiresAt,
+		usedAt:    usedAt,
+		createdAt: createdAt,
+	}
+}
+
+// HashResetToken 平文トークンから保存・検索用のハッシュを算出
+func HashResetToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// Getters
+func (t *PasswordResetToken) ID() uuid.UUID        { return t.id }
+func (t *PasswordResetToken) UserID() uuid.UUID    { return t.userID }
+func (t *PasswordResetToken) TokenHash() string    { return t.tokenHash }
+func (t *PasswordResetToken) ExpiresAt() time.Time { return t.expiresAt }
+func (t *PasswordResetToken) UsedAt() *time.Time   { return t.usedAt }
+func (t *PasswordResetToken) CreatedAt() time.Time { return t.createdAt }
+
+// Business methods
+func (t *PasswordResetToken) Use() error {
+	if t.usedAt != nil {
+		return ErrResetTokenUsed
+	}
+	now := time.Now()
+	if now.After(t.expiresAt) {
+		return ErrResetTokenExpired
+	}
+	t.usedAt = &now
+	return nil
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+	ErrRefreshTokenRevoked = errors.New("refresh token has been revoked")
+	// ErrRefreshTokenReused rotate済みのトークンが再度使用された。漏洩の可能性がある
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+)
+
+// RefreshToken アクセストークン再発行用のトークン。使用するたびに同じfamilyの新しいトークンへ置き換える。
+// 平文のトークンは保持せずハッシュのみ保存する
+type RefreshToken struct {
+	id     uuid.UUID
+	userID uuid.UUID
+	// ログイン1回ごとに発行される系列のID。再利用を検知した場合は系列全体を失効させる
+	familyID  uuid.UUID
+	tokenHash string
+	expiresAt time.Time
+	usedAt    *time.Time
+	revokedAt *time.Time
+	createdAt time.Time
+}
+
+// NewRefreshToken creates a new token and returns it with the plain token to be sent to the client
+func NewRefreshToken(userID, familyID uuid.UUID, ttl time.Duration) (*RefreshToken, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	plain := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now()
+	return &RefreshToken{
+		id:        uuid.New(),
+		userID:    userID,
+		familyID:  familyID,
+		tokenHash: HashRefreshToken(plain),
+		expiresAt: now.Add(ttl),
+		createdAt: now,
+	}, plain, nil
+}
+
+// ReconstructRefreshToken reconstructs a RefreshToken entity from persistence
+func ReconstructRefreshToken(
+	id uuid.UUID,
+	userID uuid.UUID,
+	familyID uuid.UUID,
+	tokenHash string,
+	expiresAt time.Time,
+	usedAt *time.Time,
+	revokedAt *time.Time,
+	createdAt time.Time,
+) *RefreshToken {
+	return &RefreshToken{
+		id:        id,
+		userID:    userID,
+		familyID:  familyID,
+		tokenHash: tokenHash,
+		expiresAt: expiresAt,
+		usedAt:    usedAt,
+		revokedAt: revokedAt,
+		createdAt: createdAt,
+	}
+}
+
+// HashRefreshToken 平文トークンから保存・検索用のハッシュを算出
+func HashRefreshToken(plain string) string {
+	return HashResetToken(plain)
+}
+
+// Getters
+func (t *RefreshToken) ID() uuid.UUID         { return t.id }
+func (t *RefreshToken) UserID() uuid.UUID     { return t.userID }
+func (t *RefreshToken) FamilyID() uuid.UUID   { return t.familyID }
+func (t *RefreshToken) TokenHash() string     { return t.tokenHash }
+func (t *RefreshToken) ExpiresAt() time.Time  { return t.expiresAt }
+func (t *RefreshToken) UsedAt() *time.Time    { return t.usedAt }
+func (t *RefreshToken) RevokedAt() *time.Time { return t.revokedAt }
+func (t *RefreshToken) CreatedAt() time.Time  { return t.createdAt }
+
+// Business methods
+
+// Use 新しいトークンへの置き換えのため使用済みにする。
+// 使用済みの場合は失効・期限切れより優先してErrRefreshTokenReusedを返し、呼び出し元で系列全体を失効させる
+func (t *RefreshToken) Use() error {
+	if t.usedAt != nil {
+		return ErrRefreshTokenReused
+	}
+	if t.revokedAt != nil {
+		return ErrRefreshTokenRevoked
+	}
+	now := time.Now()
+	if !now.Before(t.expiresAt) {
+		return ErrRefreshTokenExpired
+	}
+	t.usedAt = &now
+	return nil
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+)
+
+// Role ユーザー、API Keyに割り当てる役割。操作の可否はpolicyで判定する
+type Role string
+
+const (
+	// RoleAdmin 全ての操作を許可する
+	RoleAdmin Role = "admin"
+	// RoleOperator 管理者以外のユーザーの管理を許可する
+	RoleOperator Role = "operator"
+	// RoleViewer ユーザーの参照のみ許可する
+	RoleViewer Role = "viewer"
+	// RoleSelf 自分自身の参照・更新のみ許可する。エンドユーザーの既定値
+	RoleSelf Role = "self"
+)
+
+var (
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrInvalidAPIKeyRole API Keyはユーザーに紐付かないためselfは割り当てられない
+	ErrInvalidAPIKeyRole = errors.New("invalid api key role")
+)
+
+// ParseRole 文字列のroleを検証して変換する
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if !role.valid() {
+		return "", ErrInvalidRole
+	}
+	return role, nil
+}
+
+func (r Role) valid() bool {
+	switch r {
+	case RoleAdmin, RoleOperator, RoleViewer, RoleSelf:
+		return true
+	default:
+		return false
+	}
+}
+
+// Covers other以上の操作を許可するroleであればtrue。selfは他のroleを包含しない
+func (r Role) Covers(other Role) bool {
+	return r == other || r.rank() > other.rank()
+}
+
+// rank 許可する操作の広さの順序。selfは自分自身のみを対象とするため最下位とする
+func (r Role) rank() int {
+	switch r {
+	case RoleAdmin:
+		return 3
+	case RoleOperator:
+		return 2
+	case RoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// DefaultAPIKeyRole role未指定で発行するAPI Keyのrole。scopeで許可された操作に対応させる
+func DefaultAPIKeyRole(scopes []APIKeyScope) Role {
+	switch {
+	case slices.Contains(scopes, ScopeAdmin):
+		return RoleAdmin
+	case slices.Contains(scopes, ScopeUsersWrite):
+		return RoleOperator
+	default:
+		return RoleViewer
+	}
+}
+
+func validateAPIKeyRole(role Role) error {
+	if !role.valid() || role == RoleSelf {
+		return ErrInvalidAPIKeyRole
+	}
+	return nil
+}
@@ -15,6 +15,8 @@ var (
 	ErrUsernameTooShort      = errors.New("username must be at least 3 characters")
 	ErrUsernameTooLong       = errors.New("username must be at most 100 characters")
 	ErrInvalidPasswordFormat = errors.New("password must contain at least one letter and one number")
+	ErrIncorrectPassword     = errors.New("incorrect password")
+	ErrUserNotDeleted        = errors.New("user is not deleted")
 )
 
 var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
@@ -24,9 +26,13 @@ type User struct {
 	email        string
 	username     string
 	passwordHash string
+	role         Role
 	createdAt    time.Time
 	updatedAt    time.Time
 	deletedAt    *time.Time
+	version      int // 楽観的排他制御用。永続化された更新ごとに1ずつ増加
+	// 永続化前のドメインイベント。repositoryへの保存後にPullEventsで取り出す
+	events []Event
 }
 
 // NewUser creates a new User entity with validation
@@ -49,14 +55,24 @@ func NewUser(email, username, password string) (*User, error) {
 	}
 
 	now := time.Now()
-	return &User{
+	user := &User{
 		id:           uuid.New(),
 		email:        email,
 		username:     username,
 		passwordHash: hashedPassword,
+		role:         RoleSelf,
 		createdAt:    now,
 		updatedAt:    now,
-	}, nil
+		version:      1,
+	}
+	user.recordEvent(UserCreated{
+		UserID:   user.id,
+		Email:    user.email,
+		Username: user.username,
+		Role:     user.role,
+		At:       now,
+	})
+	return user, nil
 }
 
 // ReconstructUser reconstructs a User entity from persistence
@@ -65,18 +81,22 @@ func ReconstructUser(
 	email string,
 	username string,
 	passwordHash string,
+	role Role,
 	createdAt time.Time,
 	updatedAt time.Time,
 	deletedAt *time.Time,
+	version int,
 ) *User {
 	return &User{
 		id:           id,
 		email:        email,
 		username:     username,
 		passwordHash: passwordHash,
+		role:         role,
 		createdAt:    createdAt,
 		updatedAt:    updatedAt,
 		deletedAt:    deletedAt,
+		version:      version,
 	}
 }
 
@@ -85,15 +105,66 @@ func (u *User) ID() uuid.UUID         { return u.id }
 func (u *User) Email() string         { return u.email }
 func (u *User) Username() string      { return u.username }
 func (u *User) PasswordHash() string  { return u.passwordHash }
+func (u *User) Role() Role            { return u.role }
 func (u *User) CreatedAt() time.Time  { return u.createdAt }
 func (u *User) UpdatedAt() time.Time  { return u.updatedAt }
 func (u *User) DeletedAt() *time.Time { return u.deletedAt }
+func (u *User) Version() int          { return u.version }
 
 // Business methods
 func (u *User) Delete() {
 	now := time.Now()
 	u.deletedAt = &now
 	u.updatedAt = now
+	u.recordEvent(UserDeleted{UserID: u.id, At: now})
+}
+
+// HardDelete 物理削除を通知するイベントを記録する。行の削除はrepositoryで行う
+func (u *User) HardDelete() {
+	u.recordEvent(UserDeleted{UserID: u.id, Permanent: true, At: time.Now()})
+}
+
+// ChangeRole 操作の可否はpolicyで判定済みであること
+func (u *User) ChangeRole(role Role) error {
+	if !role.valid() {
+		return ErrInvalidRole
+	}
+	if role == u.role {
+		return nil
+	}
+	now := time.Now()
+	u.recordEvent(UserRoleChanged{UserID: u.id, OldRole: u.role, NewRole: role, At: now})
+	u.role = role
+	u.updatedAt = now
+	return nil
+}
+
+// Restore 論理削除を取り消す
+func (u *User) Restore() error {
+	if u.deletedAt == nil {
+		return ErrUserNotDeleted
+	}
+	now := time.Now()
+	u.deletedAt = nil
+	u.updatedAt = now
+	u.recordEvent(UserRestored{UserID: u.id, Email: u.email, Username: u.username, Role: u.role, At: now})
+	return nil
+}
+
+// IncrementVersion 更新の永続化に成功した際にrepositoryから呼び出す
+func (u *User) IncrementVersion() {
+	u.version++
+}
+
+// PullEvents 記録済みのドメインイベントを返し、エンティティからは取り除く
+func (u *User) PullEvents() []Event {
+	events := u.events
+	u.events = nil
+	return events
+}
+
+func (u *User) recordEvent(e Event) {
+	u.events = append(u.events, e)
 }
 
 func (u *User) IsDeleted() bool {
@@ -104,8 +175,13 @@ func (u *User) UpdateEmail(email string) error {
 	if err := validateEmail(email); err != nil {
 		return err
 	}
+	if email == u.email {
+		return nil
+	}
+	now := time.Now()
+	u.recordEvent(UserEmailChanged{UserID: u.id, OldEmail: u.email, NewEmail: email, At: now})
 	u.email = email
-	u.updatedAt = time.Now()
+	u.updatedAt = now
 	return nil
 }
 
@@ -113,8 +189,13 @@ func (u *User) UpdateUsername(username string) error {
 	if err := validateUsername(username); err != nil {
 		return err
 	}
+	if username == u.username {
+		return nil
+	}
+	now := time.Now()
+	u.recordEvent(UserUsernameChanged{UserID: u.id, OldUsername: u.username, NewUsername: username, At: now})
 	u.username = username
-	u.updatedAt = time.Now()
+	u.updatedAt = now
 	return nil
 }
 
@@ -123,6 +204,36 @@ func (u *User) VerifyPassword(password string) bool {
 	return err == nil
 }
 
+// ユーザーが存在しない場合の検証用のハッシュ。値自体に意味はない
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// VerifyDummyPassword ユーザーが存在しない場合もVerifyPasswordと同程度の時間をかけ、
+// 応答時間からユーザーの存在を推測させないようにする
+func VerifyDummyPassword(password string) {
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+}
+
+func (u *User) ChangePassword(oldPassword, newPassword string) error {
+	if !u.VerifyPassword(oldPassword) {
+		return ErrIncorrectPassword
+	}
+	return u.ResetPassword(newPassword)
+}
+
+// ResetPassword 現在のパスワードを確認せずに設定する。リセットトークン検証後にのみ使用すること
+func (u *User) ResetPassword(newPassword string) error {
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+	hashedPassword, err := hashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	u.passwordHash = hashedPassword
+	u.updatedAt = time.Now()
+	return nil
+}
+
 // Validation functions
 func validateEmail(email string) error {
 	if !emailRegex.MatchString(email) {
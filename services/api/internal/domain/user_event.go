@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event エンティティの状態変化を表すドメインイベント。outboxを経由して外部システムへ通知する
+type Event interface {
+	EventType() string
+	AggregateType() string
+	AggregateID() uuid.UUID
+	OccurredAt() time.Time
+}
+
+const userAggregateType = "user"
+
+// ユーザーのドメインイベントの種類
+const (
+	EventTypeUserCreated         = "user.created"
+	EventTypeUserEmailChanged    = "user.email_changed"
+	EventTypeUserUsernameChanged = "user.username_changed"
+	EventTypeUserRoleChanged     = "user.role_changed"
+	EventTypeUserDeleted         = "user.deleted"
+	EventTypeUserRestored        = "user.restored"
+)
+
+// 各イベントはJSONとしてoutboxに保存される。パスワードハッシュ等の秘匿情報は含めない
+
+type UserCreated struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Email    string    `json:"email"`
+	Username string    `json:"username"`
+	Role     Role      `json:"role"`
+	At       time.Time `json:"occurred_at"`
+}
+
+func (e UserCreated) EventType() string      { return EventTypeUserCreated }
+func (e UserCreated) AggregateType() string  { return userAggregateType }
+func (e UserCreated) AggregateID() uuid.UUID { return e.UserID }
+func (e UserCreated) OccurredAt() time.Time  { return e.At }
+
+type UserEmailChanged struct {
+	UserID   uuid.UUID `json:"user_id"`
+	OldEmail string    `json:"old_email"`
+	NewEmail string    `json:"new_email"`
+	At       time.Time `json:"occurred_at"`
+}
+
+func (e UserEmailChanged) EventType() string      { return EventTypeUserEmailChanged }
+func (e UserEmailChanged) AggregateType() string  { return userAggregateType }
+func (e UserEmailChanged) AggregateID() uuid.UUID { return e.UserID }
+func (e UserEmailChanged) OccurredAt() time.Time  { return e.At }
+
+type UserUsernameChanged struct {
+	UserID      uuid.UUID `json:"user_id"`
+	OldUsername string    `json:"old_username"`
+	NewUsername string    `json:"new_username"`
+	At          time.Time `json:"occurred_at"`
+}
+
+func (e UserUsernameChanged) EventType() string      { return EventTypeUserUsernameChanged }
+func (e UserUsernameChanged) AggregateType() string  { return userAggregateType }
+func (e UserUsernameChanged) AggregateID() uuid.UUID { return e.UserID }
+func (e UserUsernameChanged) OccurredAt() time.Time  { return e.At }
+
+type UserRoleChanged struct {
+	UserID  uuid.UUID `json:"user_id"`
+	OldRole Role      `json:"old_role"`
+	NewRole Role      `json:"new_role"`
+	At      time.Time `json:"occurred_at"`
+}
+
+func (e UserRoleChanged) EventType() string      { return EventTypeUserRoleChanged }
+func (e UserRoleChanged) AggregateType() string  { return userAggregateType }
+func (e UserRoleChanged) AggregateID() uuid.UUID { return e.UserID }
+func (e UserRoleChanged) OccurredAt() time.Time  { return e.At }
+
+type UserDeleted struct {
+	UserID uuid.UUID `json:"user_id"`
+	// trueの場合は物理削除。falseの場合は論理削除で、復元される可能性がある
+	Permanent bool      `json:"permanent"`
+	At        time.Time `json:"occurred_at"`
+}
+
+func (e UserDeleted) EventType() string      { return EventTypeUserDeleted }
+func (e UserDeleted) AggregateType() string  { return userAggregateType }
+func (e UserDeleted) AggregateID() uuid.UUID { return e.UserID }
+func (e UserDeleted) OccurredAt() time.Time  { return e.At }
+
+// UserRestored 論理削除の取り消し。削除中の変更を受け取っていない購読先が再同期できるよう、復元時点の状態を含める
+type UserRestored struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Email    string    `json:"email"`
+	Username string    `json:"username"`
+	Role     Role      `json:"role"`
+	At       time.Time `json:"occurred_at"`
+}
+
+func (e UserRestored) EventType() string      { return EventTypeUserRestored }
+func (e UserRestored) AggregateType() string  { return userAggregateType }
+func (e UserRestored) AggregateID() uuid.UUID { return e.UserID }
+func (e UserRestored) OccurredAt() time.Time  { return e.At }
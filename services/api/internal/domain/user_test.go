@@ -0,0 +1,126 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+func newTestUser(t *testing.T, deleted bool) *domain.User {
+	t.Helper()
+	now := time.Now()
+	var deletedAt *time.Time
+	if deleted {
+		deletedAt = &now
+	}
+	return domain.ReconstructUser(uuid.New(), "alice@example.com", "alice", "dummy-hash", domain.RoleSelf, now, now, deletedAt, 1)
+}
+
+// pullOnlyEvent 記録されたイベントが1件のみであることを確認して返す
+func pullOnlyEvent(t *testing.T, user *domain.User) domain.Event {
+	t.Helper()
+	events := user.PullEvents()
+	if len(events) != 1 {
+		t.Fatalf("recorded %d events, want 1: %#v", len(events), events)
+	}
+	if events[0].AggregateID() != user.ID() {
+		t.Errorf("AggregateID = %s, want %s", events[0].AggregateID(), user.ID())
+	}
+	return events[0]
+}
+
+func TestUserRestoreRecordsEvent(t *testing.T) {
+	user := newTestUser(t, true)
+	if err := user.Restore(); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	event, ok := pullOnlyEvent(t, user).(domain.UserRestored)
+	if !ok {
+		t.Fatalf("event is not UserRestored")
+	}
+	if event.EventType() != domain.EventTypeUserRestored {
+		t.Errorf("EventType = %q, want %q", event.EventType(), domain.EventTypeUserRestored)
+	}
+	if event.Email != user.Email() || event.Username != user.Username() || event.Role != user.Role() {
+		t.Errorf("event = %+v, want the restored state of the user", event)
+	}
+}
+
+func TestUserRestoreNotDeleted(t *testing.T) {
+	user := newTestUser(t, false)
+	if err := user.Restore(); err == nil {
+		t.Fatal("Restore of an active user succeeded")
+	}
+	if events := user.PullEvents(); len(events) != 0 {
+		t.Errorf("recorded %d events, want 0", len(events))
+	}
+}
+
+func TestUserChangeRoleRecordsEvent(t *testing.T) {
+	user := newTestUser(t, false)
+	if err := user.ChangeRole(domain.RoleOperator); err != nil {
+		t.Fatalf("ChangeRole: %v", err)
+	}
+
+	event, ok := pullOnlyEvent(t, user).(domain.UserRoleChanged)
+	if !ok {
+		t.Fatalf("event is not UserRoleChanged")
+	}
+	if event.EventType() != domain.EventTypeUserRoleChanged {
+		t.Errorf("EventType = %q, want %q", event.EventType(), domain.EventTypeUserRoleChanged)
+	}
+	if event.OldRole != domain.RoleSelf || event.NewRole != domain.RoleOperator {
+		t.Errorf("roles = %q -> %q, want %q -> %q", event.OldRole, event.NewRole, domain.RoleSelf, domain.RoleOperator)
+	}
+
+	// 同じroleへの変更ではイベントを記録しない
+	if err := user.ChangeRole(domain.RoleOperator); err != nil {
+		t.Fatalf("ChangeRole: %v", err)
+	}
+	if events := user.PullEvents(); len(events) != 0 {
+		t.Errorf("recorded %d events for an unchanged role, want 0", len(events))
+	}
+}
+
+func TestUserUpdateUsernameRecordsEvent(t *testing.T) {
+	user := newTestUser(t, false)
+	if err := user.UpdateUsername("alice2"); err != nil {
+		t.Fatalf("UpdateUsername: %v", err)
+	}
+
+	event, ok := pullOnlyEvent(t, user).(domain.UserUsernameChanged)
+	if !ok {
+		t.Fatalf("event is not UserUsernameChanged")
+	}
+	if event.EventType() != domain.EventTypeUserUsernameChanged {
+		t.Errorf("EventType = %q, want %q", event.EventType(), domain.EventTypeUserUsernameChanged)
+	}
+	if event.OldUsername != "alice" || event.NewUsername != "alice2" {
+		t.Errorf("usernames = %q -> %q, want %q -> %q", event.OldUsername, event.NewUsername, "alice", "alice2")
+	}
+
+	if err := user.UpdateUsername("alice2"); err != nil {
+		t.Fatalf("UpdateUsername: %v", err)
+	}
+	if events := user.PullEvents(); len(events) != 0 {
+		t.Errorf("recorded %d events for an unchanged username, want 0", len(events))
+	}
+}
+
+// 購読できるイベントの種類は、ユーザーの全てのドメインイベントを含む
+func TestWebhookSubscribesToUserEvents(t *testing.T) {
+	eventTypes := []string{
+		domain.EventTypeUserCreated,
+		domain.EventTypeUserEmailChanged,
+		domain.EventTypeUserUsernameChanged,
+		domain.EventTypeUserRoleChanged,
+		domain.EventTypeUserDeleted,
+		domain.EventTypeUserRestored,
+	}
+	if _, err := domain.NewWebhookSubscription("https://example.com/hook", eventTypes, "0123456789abcdef"); err != nil {
+		t.Errorf("NewWebhookSubscription: %v", err)
+	}
+}
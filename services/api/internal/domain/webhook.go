@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 生成する署名用シークレットの接頭辞
+const webhookSecretPrefix = "whsec_"
+
+// 推測されにくいよう、指定されたシークレットに求める最小の長さ
+const minWebhookSecretLength = 16
+
+var (
+	ErrInvalidWebhookURL       = errors.New("invalid webhook url")
+	ErrWebhookURLNotPublic     = errors.New("webhook url must not point to a loopback, link-local or private address")
+	ErrInvalidWebhookEventType = errors.New("invalid webhook event type")
+	ErrWebhookEventTypesEmpty  = errors.New("webhook must subscribe to at least one event type")
+	ErrWebhookSecretTooShort   = errors.New("webhook secret must be at least 16 characters")
+)
+
+// webhookEventTypes 購読できるイベントの種類
+var webhookEventTypes = []string{
+	EventTypeUserCreated,
+	EventTypeUserEmailChanged,
+	EventTypeUserUsernameChanged,
+	EventTypeUserRoleChanged,
+	EventTypeUserDeleted,
+	EventTypeUserRestored,
+}
+
+// WebhookSubscription ドメインイベントをHTTPで通知する購読。
+// シークレットは配信時の署名(HMAC-SHA256)に使用するため、ハッシュではなくそのまま保持する
+type WebhookSubscription struct {
+	id         uuid.UUID
+	url        string
+	eventTypes []string
+	secret     string
+	active     bool
+	createdAt  time.Time
+	updatedAt  time.Time
+}
+
+// NewWebhookSubscription secretが空の場合は生成する
+func NewWebhookSubscription(rawURL string, eventTypes []string, secret string) (*WebhookSubscription, error) {
+	if err := validateWebhookURL(rawURL); err != nil {
+		return nil, err
+	}
+	if err := validateWebhookEventTypes(eventTypes); err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		generated, err := generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	} else if len(secret) < minWebhookSecretLength {
+		return nil, ErrWebhookSecretTooShort
+	}
+
+	now := time.Now()
+	return &WebhookSubscription{
+		id:         uuid.New(),
+		url:        rawURL,
+		eventTypes: slices.Clone(eventTypes),
+		secret:     secret,
+		active:     true,
+		createdAt:  now,
+		updatedAt:  now,
+	}, nil
+}
+
+// ReconstructWebhookSubscription reconstructs a WebhookSubscription entity from persistence
+func ReconstructWebhookSubscription(
+	id uuid.UUID,
+	rawURL string,
+	eventTypes []string,
+	secret string,
+	active bool,
+	createdAt time.Time,
+	updatedAt time.Time,
+) *WebhookSubscription {
+	return &WebhookSubscription{
+		id:         id,
+		url:        rawURL,
+		eventTypes: slices.Clone(eventTypes),
+		secret:     secret,
+		active:     active,
+		createdAt:  createdAt,
+		updatedAt:  updatedAt,
+	}
+}
+
+// Getters
+func (w *WebhookSubscription) ID() uuid.UUID        { return w.id }
+func (w *WebhookSubscription) URL() string          { return w.url }
+func (w *WebhookSubscription) EventTypes() []string { return slices.Clone(w.eventTypes) }
+func (w *WebhookSubscription) Secret() string       { return w.secret }
+func (w *WebhookSubscription) Active() bool         { return w.active }
+func (w *WebhookSubscription) CreatedAt() time.Time { return w.createdAt }
+func (w *WebhookSubscription) UpdatedAt() time.Time { return w.updatedAt }
+
+// Business methods
+func (w *WebhookSubscription) UpdateURL(rawURL string) error {
+	if err := validateWebhookURL(rawURL); err != nil {
+		return err
+	}
+	w.url = rawURL
+	w.updatedAt = time.Now()
+	return nil
+}
+
+func (w *WebhookSubscription) UpdateEventTypes(eventTypes []string) error {
+	if err := validateWebhookEventTypes(eventTypes); err != nil {
+		return err
+	}
+	w.eventTypes = slices.Clone(eventTypes)
+	w.updatedAt = time.Now()
+	return nil
+}
+
+// SetActive 無効化した購読には以降のイベントを配信しない。配信待ちのものは有効に戻すまで送信を保留する
+func (w *WebhookSubscription) SetActive(active bool) {
+	w.active = active
+	w.updatedAt = time.Now()
+}
+
+// validateWebhookURL 内部のサービスへリクエストを送信させない(SSRF)よう、ホストが内部のアドレスであるURLも拒否する。
+// ホスト名が内部のアドレスに解決される場合は、services/batchが送信時に拒否する
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Hostname() == "" {
+		return ErrInvalidWebhookURL
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrWebhookURLNotPublic
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !isPublicAddr(addr) {
+		return ErrWebhookURLNotPublic
+	}
+	return nil
+}
+
+// nonPublicPrefixes netipで判定できない、インターネットから到達できないアドレスの範囲
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// isPublicAddr ループバック、リンクローカル、プライベート等のアドレスではない場合true
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func validateWebhookEventTypes(eventTypes []string) error {
+	if len(eventTypes) == 0 {
+		return ErrWebhookEventTypesEmpty
+	}
+	for _, t := range eventTypes {
+		if !slices.Contains(webhookEventTypes, t) {
+			return fmt.Errorf("%w: %q", ErrInvalidWebhookEventType, t)
+		}
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
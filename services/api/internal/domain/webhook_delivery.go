@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookDeliveryStatus 配信の状態
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending 配信待ち、または再試行待ち
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliverySucceeded 2xxの応答を受け取った
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryDead 再試行の上限に達した(dead letter)。再送はReplayで行う
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// ErrWebhookDeliveryPending 配信待ちの配信は再送できない
+var ErrWebhookDeliveryPending = errors.New("webhook delivery is still pending")
+
+// WebhookDelivery ドメインイベント1件の購読1件への配信。
+// 作成と送信はservices/batchのoutbox-relay、webhook-dispatcherが行う
+type WebhookDelivery struct {
+	id             uuid.UUID
+	subscriptionID uuid.UUID
+	eventID        uuid.UUID
+	eventType      string
+	// 送信するリクエストボディ
+	payload        json.RawMessage
+	status         WebhookDeliveryStatus
+	attempts       int
+	nextAttemptAt  *time.Time
+	lastAttemptAt  *time.Time
+	lastStatusCode *int
+	lastError      string
+	createdAt      time.Time
+	updatedAt      time.Time
+}
+
+// WebhookDeliveryAttempt 送信1回分の記録
+type WebhookDeliveryAttempt struct {
+	AttemptedAt time.Time
+	// 応答を受け取れなかった場合はnil
+	StatusCode *int
+	// 2xxの応答の場合は空
+	Error    string
+	Duration time.Duration
+}
+
+// ReconstructWebhookDelivery reconstructs a WebhookDelivery entity from persistence
+func ReconstructWebhookDelivery(
+	id uuid.UUID,
+	subscriptionID uuid.UUID,
+	eventID uuid.UUID,
+	eventType string,
+	payload json.RawMessage,
+	status WebhookDeliveryStatus,
+	attempts int,
+	nextAttemptAt *time.Time,
+	lastAttemptAt *time.Time,
+	lastStatusCode *int,
+	lastError string,
+	createdAt time.Time,
+	updatedAt time.Time,
+) *WebhookDelivery {
+	return &WebhookDelivery{
+		id:             id,
+		subscriptionID: subscriptionID,
+		eventID:        eventID,
+		eventType:      eventType,
+		payload:        payload,
+		status:         status,
+		attempts:       attempts,
+		nextAttemptAt:  nextAttemptAt,
+		lastAttemptAt:  lastAttemptAt,
+		lastStatusCode: lastStatusCode,
+		lastError:      lastError,
+		createdAt:      createdAt,
+		updatedAt:      updatedAt,
+	}
+}
+
+// Getters
+func (d *WebhookDelivery) ID() uuid.UUID                 { return d.id }
+func (d *WebhookDelivery) SubscriptionID() uuid.UUID     { return d.subscriptionID }
+func (d *WebhookDelivery) EventID() uuid.UUID            { return d.eventID }
+func (d *WebhookDelivery) EventType() string             { return d.eventType }
+func (d *WebhookDelivery) Payload() json.RawMessage      { return d.payload }
+func (d *WebhookDelivery) Status() WebhookDeliveryStatus { return d.status }
+func (d *WebhookDelivery) Attempts() int                 { return d.attempts }
+func (d *WebhookDelivery) NextAttemptAt() *time.Time     { return d.nextAttemptAt }
+func (d *WebhookDelivery) LastAttemptAt() *time.Time     { return d.lastAttemptAt }
+func (d *WebhookDelivery) LastStatusCode() *int          { return d.lastStatusCode }
+func (d *WebhookDelivery) LastError() string             { return d.lastError }
+func (d *WebhookDelivery) CreatedAt() time.Time          { return d.createdAt }
+func (d *WebhookDelivery) UpdatedAt() time.Time          { return d.updatedAt }
+
+// Replay 配信済み、またはdead letterの配信を再送する。再試行回数は初期化し、送信の記録は残す
+func (d *WebhookDelivery) Replay() error {
+	if d.status == WebhookDeliveryPending {
+		return ErrWebhookDeliveryPending
+	}
+	now := time.Now()
+	d.status = WebhookDeliveryPending
+	d.attempts = 0
+	d.nextAttemptAt = &now
+	d.updatedAt = now
+	return nil
+}
@@ -0,0 +1,52 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+func TestNewWebhookSubscriptionURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr error
+	}{
+		{"https://example.com/hook", nil},
+		{"http://203.0.113.10:8080/hook", nil},
+		{"ftp://example.com/hook", domain.ErrInvalidWebhookURL},
+		{"/hook", domain.ErrInvalidWebhookURL},
+		{"http://localhost:8080/hook", domain.ErrWebhookURLNotPublic},
+		{"http://api.localhost/hook", domain.ErrWebhookURLNotPublic},
+		{"http://127.0.0.1/hook", domain.ErrWebhookURLNotPublic},
+		{"http://[::1]/hook", domain.ErrWebhookURLNotPublic},
+		{"http://169.254.169.254/latest/meta-data", domain.ErrWebhookURLNotPublic},
+		{"http://10.0.0.5/hook", domain.ErrWebhookURLNotPublic},
+		{"http://192.168.1.1/hook", domain.ErrWebhookURLNotPublic},
+		{"http://[fd00::1]/hook", domain.ErrWebhookURLNotPublic},
+		{"http://[::ffff:127.0.0.1]/hook", domain.ErrWebhookURLNotPublic},
+		{"http://0.0.0.0/hook", domain.ErrWebhookURLNotPublic},
+		{"http://100.64.0.1/hook", domain.ErrWebhookURLNotPublic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			_, err := domain.NewWebhookSubscription(tt.url, []string{domain.EventTypeUserCreated}, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookSubscriptionUpdateURLRejectsPrivateAddress(t *testing.T) {
+	subscription, err := domain.NewWebhookSubscription("https://example.com/hook", []string{domain.EventTypeUserCreated}, "")
+	if err != nil {
+		t.Fatalf("NewWebhookSubscription: %v", err)
+	}
+	if err := subscription.UpdateURL("http://169.254.169.254/"); !errors.Is(err, domain.ErrWebhookURLNotPublic) {
+		t.Errorf("UpdateURL error = %v, want %v", err, domain.ErrWebhookURLNotPublic)
+	}
+	if got := subscription.URL(); got != "https://example.com/hook" {
+		t.Errorf("URL = %q, want unchanged", got)
+	}
+}
@@ -0,0 +1,17 @@
+package query
+
+import "time"
+
+type ListAuditEvents struct {
+	Limit int `form:"limit,default=20" binding:"min=1,max=100"`
+	// 前回レスポンスのnext_cursor
+	Cursor string `form:"cursor"`
+
+	// 絞り込み条件
+	ActorType    string     `form:"actor_type" binding:"omitempty,oneof=api_key user system"`
+	ActorID      string     `form:"actor_id" binding:"max=64"`
+	Action       string     `form:"action" binding:"max=64"`
+	TargetUserID string     `form:"target_user_id" binding:"omitempty,uuid"`
+	From         *time.Time `form:"from"` // RFC3339。指定時刻を含む
+	To           *time.Time `form:"to"`   // RFC3339。指定時刻を含まない
+}
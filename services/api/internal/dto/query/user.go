@@ -1,6 +1,29 @@
 package query
 
+import "time"
+
 type ListUsers struct {
-	Limit  int `form:"limit,default=10" binding:"min=1,max=100"`
+	Limit int `form:"limit,default=10" binding:"min=1,max=100"`
+	// 後方互換のため残している。Cursor指定時は無視される
 	Offset int `form:"offset,default=0" binding:"min=0"`
+	// 前回レスポンスのnext_cursor。指定時はkeyset paginationとなる
+	Cursor string `form:"cursor"`
+	// trueの場合、totalを算出しない(COUNT(*)を省略)
+	SkipTotal bool `form:"skip_total,default=false"`
+
+	// 絞り込み条件
+	EmailDomain      string     `form:"email_domain" binding:"max=255"`
+	UsernamePrefix   string     `form:"username_prefix" binding:"max=100"`
+	UsernameContains string     `form:"username_contains" binding:"max=100"`
+	CreatedFrom      *time.Time `form:"created_from"` // RFC3339。指定時刻を含む
+	CreatedTo        *time.Time `form:"created_to"`   // RFC3339。指定時刻を含まない
+	IncludeDeleted   bool       `form:"include_deleted,default=false"`
+
+	// ソート条件
+	Sort  string `form:"sort,default=created_at" binding:"oneof=created_at updated_at username email"`
+	Order string `form:"order,default=desc" binding:"oneof=asc desc"`
+}
+
+type GetUserByEmail struct {
+	Email string `form:"email" binding:"required,email"`
 }
@@ -0,0 +1,8 @@
+package query
+
+type ListWebhookDeliveries struct {
+	Limit int `form:"limit,default=20" binding:"min=1,max=100"`
+	// 前回レスポンスのnext_cursor
+	Cursor string `form:"cursor"`
+	Status string `form:"status" binding:"omitempty,oneof=pending succeeded dead"`
+}
@@ -0,0 +1,18 @@
+package request
+
+import "time"
+
+type IssueAPIKey struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Owner  string   `json:"owner" binding:"required,max=255"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=users:read users:write admin"`
+	// 省略時はscopeに対応するroleとする(admin→admin, users:write→operator, その他→viewer)
+	Role string `json:"role" binding:"omitempty,oneof=admin operator viewer"`
+	// 省略時は無期限
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type RotateAPIKey struct {
+	// 旧キーを失効させるまでの猶予(秒)。クライアントのキー差し替え中の失敗を防ぐ。最大7日
+	GracePeriodSeconds int `json:"grace_period_seconds" binding:"min=0,max=604800"`
+}
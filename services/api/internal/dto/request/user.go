@@ -5,3 +5,37 @@ type CreateUser struct {
 	Username string `json:"username" binding:"required,min=3,max=100"`
 	Password string `json:"password" binding:"required,min=8"`
 }
+
+// UpdateUser 部分更新用。nilのフィールドは更新しない。いずれもnilの場合は400とする
+type UpdateUser struct {
+	Email    *string `json:"email" binding:"omitempty,email"`
+	Username *string `json:"username" binding:"omitempty,min=3,max=100"`
+}
+
+type ChangeRole struct {
+	Role string `json:"role" binding:"required,oneof=admin operator viewer self"`
+}
+
+type ChangePassword struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+type RequestPasswordReset struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ConfirmPasswordReset struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+type Login struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshToken /auth/refresh, /auth/logout用
+type RefreshToken struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
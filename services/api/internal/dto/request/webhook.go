@@ -0,0 +1,15 @@
+package request
+
+type CreateWebhook struct {
+	URL        string   `json:"url" binding:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,dive,oneof=user.created user.email_changed user.username_changed user.role_changed user.deleted user.restored"`
+	// 署名用のシークレット。省略時は生成する
+	Secret string `json:"secret" binding:"omitempty,min=16,max=128"`
+}
+
+// UpdateWebhook 省略した項目は変更しない
+type UpdateWebhook struct {
+	URL        *string  `json:"url" binding:"omitempty,url,max=2048"`
+	EventTypes []string `json:"event_types" binding:"omitempty,min=1,dive,oneof=user.created user.email_changed user.username_changed user.role_changed user.deleted user.restored"`
+	Active     *bool    `json:"active"`
+}
@@ -0,0 +1,59 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+// APIKey 平文のキーは含まない
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Owner      string     `json:"owner"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Role       string     `json:"role"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewAPIKeyFromDomain(key *domain.APIKey) APIKey {
+	scopes := make([]string, 0, len(key.Scopes()))
+	for _, s := range key.Scopes() {
+		scopes = append(scopes, string(s))
+	}
+	return APIKey{
+		ID:         key.ID(),
+		Name:       key.Name(),
+		Owner:      key.Owner(),
+		Prefix:     key.Prefix(),
+		Scopes:     scopes,
+		Role:       string(key.Role()),
+		ExpiresAt:  key.ExpiresAt(),
+		LastUsedAt: key.LastUsedAt(),
+		RevokedAt:  key.RevokedAt(),
+		CreatedAt:  key.CreatedAt(),
+	}
+}
+
+// IssuedAPIKey 発行時のみ平文のキーを返す。再取得はできない
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type APIKeyList struct {
+	APIKeys []APIKey `json:"api_keys"`
+}
+
+func NewAPIKeyListFromDomain(keys []*domain.APIKey) APIKeyList {
+	responses := make([]APIKey, len(keys))
+	for i, key := range keys {
+		responses[i] = NewAPIKeyFromDomain(key)
+	}
+	return APIKeyList{APIKeys: responses}
+}
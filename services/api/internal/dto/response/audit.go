@@ -0,0 +1,60 @@
+package response
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+type AuditActor struct {
+	Type string `json:"type"`
+	// systemの場合は省略
+	ID string `json:"id,omitempty"`
+}
+
+type AuditEvent struct {
+	ID           uuid.UUID  `json:"id"`
+	OccurredAt   time.Time  `json:"occurred_at"`
+	Actor        AuditActor `json:"actor"`
+	Action       string     `json:"action"`
+	TargetUserID uuid.UUID  `json:"target_user_id"`
+	// 変更されたフィールドの変更前後の値。作成時のbefore、物理削除時のafterはnull
+	Before    map[string]any `json:"before"`
+	After     map[string]any `json:"after"`
+	RequestID string         `json:"request_id,omitempty"`
+	ClientIP  string         `json:"client_ip,omitempty"`
+}
+
+func NewAuditEventFromDomain(event *domain.AuditEvent) AuditEvent {
+	return AuditEvent{
+		ID:         event.ID(),
+		OccurredAt: event.OccurredAt(),
+		Actor: AuditActor{
+			Type: string(event.Actor().Type),
+			ID:   event.Actor().ID,
+		},
+		Action:       string(event.Action()),
+		TargetUserID: event.TargetUserID(),
+		Before:       event.Before(),
+		After:        event.After(),
+		RequestID:    event.RequestID(),
+		ClientIP:     event.ClientIP(),
+	}
+}
+
+type AuditEventList struct {
+	Events     []AuditEvent `json:"events"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+func NewAuditEventListFromDomain(events []*domain.AuditEvent, nextCursor string) AuditEventList {
+	responses := make([]AuditEvent, len(events))
+	for i, event := range events {
+		responses[i] = NewAuditEventFromDomain(event)
+	}
+	return AuditEventList{
+		Events:     responses,
+		NextCursor: nextCursor,
+	}
+}
@@ -0,0 +1,12 @@
+package response
+
+import "time"
+
+type TokenPair struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	// アクセストークンの有効期間(秒)
+	ExpiresIn             int64     `json:"expires_in"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
@@ -6,6 +6,20 @@ type Error struct {
 	Code      string    `json:"code"`
 	Message   string    `json:"message"`
 	Timestamp time.Time `json:"timestamp"`
+	// リクエストの項目ごとのエラー。INVALID_REQUESTの場合のみ設定される
+	Details []ErrorDetail `json:"details,omitempty"`
+}
+
+// ErrorDetail リクエストの項目1件分のエラー
+type ErrorDetail struct {
+	// JSONのキー、またはクエリパラメータ名。ネストした項目は"."区切り、配列の要素は"[0]"で表す。
+	// リクエスト全体に対するエラーの場合は空文字
+	Field string `json:"field"`
+	// 違反したルール(required, min, max, oneof, email, type, unknown, syntax等)
+	Rule string `json:"rule"`
+	// ルールのパラメータ(minの下限値、typeの期待する型等)
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
 }
 
 func NewError(code, message string) Error {
@@ -15,3 +29,10 @@ func NewError(code, message string) Error {
 		Timestamp: time.Now(),
 	}
 }
+
+// NewInvalidRequestError 項目ごとのエラーを含むINVALID_REQUESTのエラー
+func NewInvalidRequestError(details []ErrorDetail) Error {
+	e := NewError("INVALID_REQUEST", "リクエストが不正です")
+	e.Details = details
+	return e
+}
@@ -0,0 +1,8 @@
+package response
+
+import "time"
+
+type PasswordResetToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
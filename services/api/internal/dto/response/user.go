@@ -11,8 +11,11 @@ type User struct {
 	ID        uuid.UUID `json:"id"`
 	Email     string    `json:"email"`
 	Username  string    `json:"username"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// include_deleted指定時の論理削除済みユーザーのみ設定される
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 func NewUserFromDomain(user *domain.User) User {
@@ -20,23 +23,28 @@ func NewUserFromDomain(user *domain.User) User {
 		ID:        user.ID(),
 		Email:     user.Email(),
 		Username:  user.Username(),
+		Role:      string(user.Role()),
 		CreatedAt: user.CreatedAt(),
 		UpdatedAt: user.UpdatedAt(),
+		DeletedAt: user.DeletedAt(),
 	}
 }
 
 type UserList struct {
 	Users []User `json:"users"`
-	Total int    `json:"total"`
+	// skip_total指定時は省略
+	Total      *int   `json:"total,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
-func NewUserListFromDomain(users []*domain.User, total int) UserList {
+func NewUserListFromDomain(users []*domain.User, total *int, nextCursor string) UserList {
 	responses := make([]User, len(users))
 	for i, user := range users {
 		responses[i] = NewUserFromDomain(user)
 	}
 	return UserList{
-		Users: responses,
-		Total: total,
+		Users:      responses,
+		Total:      total,
+		NextCursor: nextCursor,
 	}
 }
@@ -0,0 +1,127 @@
+package response
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+// WebhookSubscription 署名用のシークレットは含まない
+type WebhookSubscription struct {
+	ID         uuid.UUID `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func NewWebhookSubscriptionFromDomain(subscription *domain.WebhookSubscription) WebhookSubscription {
+	return WebhookSubscription{
+		ID:         subscription.ID(),
+		URL:        subscription.URL(),
+		EventTypes: subscription.EventTypes(),
+		Active:     subscription.Active(),
+		CreatedAt:  subscription.CreatedAt(),
+		UpdatedAt:  subscription.UpdatedAt(),
+	}
+}
+
+// CreatedWebhookSubscription 作成時のみシークレットを返す。再取得はできない
+type CreatedWebhookSubscription struct {
+	WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+type WebhookSubscriptionList struct {
+	Webhooks []WebhookSubscription `json:"webhooks"`
+}
+
+func NewWebhookSubscriptionListFromDomain(subscriptions []*domain.WebhookSubscription) WebhookSubscriptionList {
+	responses := make([]WebhookSubscription, len(subscriptions))
+	for i, subscription := range subscriptions {
+		responses[i] = NewWebhookSubscriptionFromDomain(subscription)
+	}
+	return WebhookSubscriptionList{Webhooks: responses}
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID  `json:"id"`
+	WebhookID      uuid.UUID  `json:"webhook_id"`
+	EventID        uuid.UUID  `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	LastStatusCode *int       `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func NewWebhookDeliveryFromDomain(delivery *domain.WebhookDelivery) WebhookDelivery {
+	return WebhookDelivery{
+		ID:             delivery.ID(),
+		WebhookID:      delivery.SubscriptionID(),
+		EventID:        delivery.EventID(),
+		EventType:      delivery.EventType(),
+		Status:         string(delivery.Status()),
+		Attempts:       delivery.Attempts(),
+		NextAttemptAt:  delivery.NextAttemptAt(),
+		LastAttemptAt:  delivery.LastAttemptAt(),
+		LastStatusCode: delivery.LastStatusCode(),
+		LastError:      delivery.LastError(),
+		CreatedAt:      delivery.CreatedAt(),
+		UpdatedAt:      delivery.UpdatedAt(),
+	}
+}
+
+type WebhookDeliveryList struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+func NewWebhookDeliveryListFromDomain(deliveries []*domain.WebhookDelivery, nextCursor string) WebhookDeliveryList {
+	responses := make([]WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		responses[i] = NewWebhookDeliveryFromDomain(delivery)
+	}
+	return WebhookDeliveryList{
+		Deliveries: responses,
+		NextCursor: nextCursor,
+	}
+}
+
+type WebhookDeliveryAttempt struct {
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  *int      `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+}
+
+// WebhookDeliveryDetail 送信したリクエストボディと送信履歴を含む
+type WebhookDeliveryDetail struct {
+	WebhookDelivery
+	Payload json.RawMessage          `json:"payload"`
+	History []WebhookDeliveryAttempt `json:"attempt_history"`
+}
+
+func NewWebhookDeliveryDetailFromDomain(delivery *domain.WebhookDelivery, attempts []domain.WebhookDeliveryAttempt) WebhookDeliveryDetail {
+	history := make([]WebhookDeliveryAttempt, len(attempts))
+	for i, attempt := range attempts {
+		history[i] = WebhookDeliveryAttempt{
+			AttemptedAt: attempt.AttemptedAt,
+			StatusCode:  attempt.StatusCode,
+			Error:       attempt.Error,
+			DurationMS:  attempt.Duration.Milliseconds(),
+		}
+	}
+	return WebhookDeliveryDetail{
+		WebhookDelivery: NewWebhookDeliveryFromDomain(delivery),
+		Payload:         delivery.Payload(),
+		History:         history,
+	}
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+	"github.com/tokane888/test-mcp/services/api/internal/usecase"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) IssueAPIKey(c *gin.Context) {
+	var req request.IssueAPIKey
+	if err := bindJSON(c, &req); err != nil {
+		writeBindingError(c, err)
+		return
+	}
+
+	key, plain, err := h.apiKeyUseCase.IssueAPIKey(c.Request.Context(), &req)
+	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrInvalidAPIKeyRequest) {
+			c.JSON(http.StatusBadRequest, response.NewError("INVALID_REQUEST", "リクエストが不正です"))
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to issue api key", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.IssuedAPIKey{
+		APIKey: response.NewAPIKeyFromDomain(key),
+		Key:    plain,
+	})
+}
+
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyUseCase.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		pkglogger.FromContext(c.Request.Context()).Error("failed to list api keys", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewAPIKeyListFromDomain(keys))
+}
+
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return
+	}
+
+	key, err := h.apiKeyUseCase.RevokeAPIKey(c.Request.Context(), id)
+	if err != nil {
+		if writeAPIKeyError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to revoke api key", zap.Error(err), zap.String("api_key_id", id.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewAPIKeyFromDomain(key))
+}
+
+func (h *Handler) RotateAPIKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return
+	}
+
+	var req request.RotateAPIKey
+	// bodyは省略可能(猶予期間なし)
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &req); err != nil {
+			writeBindingError(c, err)
+			return
+		}
+	}
+
+	key, plain, err := h.apiKeyUseCase.RotateAPIKey(c.Request.Context(), id, &req)
+	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
+		if writeAPIKeyError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to rotate api key", zap.Error(err), zap.String("api_key_id", id.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.IssuedAPIKey{
+		APIKey: response.NewAPIKeyFromDomain(key),
+		Key:    plain,
+	})
+}
+
+// writeAPIKeyError API Key操作に共通するエラーのレスポンスを返す。対応するエラーでない場合はfalse
+func writeAPIKeyError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, usecase.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, response.NewError("API_KEY_NOT_FOUND", "API Keyが見つかりません"))
+	case errors.Is(err, usecase.ErrAPIKeyAlreadyRevoked):
+		c.JSON(http.StatusConflict, response.NewError("API_KEY_REVOKED", "API Keyは既に失効しています"))
+	case errors.Is(err, usecase.ErrAPIKeyExpired):
+		c.JSON(http.StatusConflict, response.NewError("API_KEY_EXPIRED", "API Keyは有効期限が切れています。新たに発行してください"))
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/query"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+	"github.com/tokane888/test-mcp/services/api/internal/usecase"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) ListAuditEvents(c *gin.Context) {
+	var q query.ListAuditEvents
+	if err := c.ShouldBindQuery(&q); err != nil {
+		writeQueryBindingError(c, err, &q)
+		return
+	}
+
+	list, err := h.auditUseCase.ListAuditEvents(c.Request.Context(), &q)
+	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, response.NewError("INVALID_CURSOR", "カーソルが不正です"))
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to list audit events", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewAuditEventListFromDomain(list.Events, list.NextCursor))
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+	"github.com/tokane888/test-mcp/services/api/internal/router/middleware"
+	"github.com/tokane888/test-mcp/services/api/internal/usecase"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) Login(c *gin.Context) {
+	var req request.Login
+	if err := bindJSON(c, &req); err != nil {
+		writeBindingError(c, err)
+		return
+	}
+
+	pair, err := h.authUseCase.Login(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, response.NewError("INVALID_CREDENTIALS", "メールアドレスまたはパスワードが正しくありません"))
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to login", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	writeTokenPair(c, pair)
+}
+
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var req request.RefreshToken
+	if err := bindJSON(c, &req); err != nil {
+		writeBindingError(c, err)
+		return
+	}
+
+	pair, err := h.authUseCase.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		// 再利用の検知はログにのみ記録し、クライアントには通常の無効なトークンとして返す
+		if errors.Is(err, usecase.ErrInvalidRefreshToken) || errors.Is(err, usecase.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, response.NewError("INVALID_REFRESH_TOKEN", "無効なリフレッシュトークンです"))
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to refresh token", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	writeTokenPair(c, pair)
+}
+
+func (h *Handler) Logout(c *gin.Context) {
+	var req request.RefreshToken
+	if err := bindJSON(c, &req); err != nil {
+		writeBindingError(c, err)
+		return
+	}
+
+	if err := h.authUseCase.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		pkglogger.FromContext(c.Request.Context()).Error("failed to logout", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// JWKS アクセストークンの検証に使用する公開鍵を返す
+func (h *Handler) JWKS(c *gin.Context) {
+	// 鍵のrotate時に反映が遅れすぎないよう、短時間のみキャッシュさせる
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keySet.JWKS())
+}
+
+// GetMe アクセストークンで認証したユーザー自身の情報を返す
+func (h *Handler) GetMe(c *gin.Context) {
+	id, ok := middleware.UserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.NewError("MISSING_ACCESS_TOKEN", "アクセストークンが指定されていません"))
+		return
+	}
+
+	user, err := h.userUseCase.GetUser(c.Request.Context(), id)
+	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
+		// トークンの発行後に削除されたユーザー
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			c.JSON(http.StatusUnauthorized, response.NewError("INVALID_ACCESS_TOKEN", "無効なアクセストークンです"))
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to get user", zap.Error(err), zap.String("user_id", id.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	setETag(c, user)
+	c.JSON(http.StatusOK, response.NewUserFromDomain(user))
+}
+
+// UpdateMe アクセストークンで認証したユーザー自身の情報を更新する
+func (h *Handler) UpdateMe(c *gin.Context) {
+	id, ok := middleware.UserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.NewError("MISSING_ACCESS_TOKEN", "アクセストークンが指定されていません"))
+		return
+	}
+	h.updateUser(c, id)
+}
+
+// ChangeMyPassword アクセストークンで認証したユーザー自身のパスワードを変更する
+func (h *Handler) ChangeMyPassword(c *gin.Context) {
+	id, ok := middleware.UserIDFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, response.NewError("MISSING_ACCESS_TOKEN", "アクセストークンが指定されていません"))
+		return
+	}
+	h.changePassword(c, id)
+}
+
+func writeTokenPair(c *gin.Context, pair *usecase.TokenPair) {
+	// トークンをキャッシュさせない(RFC 6749 5.1)
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response.TokenPair{
+		AccessToken:           pair.AccessToken,
+		TokenType:             "Bearer",
+		ExpiresIn:             int64(time.Until(pair.AccessTokenExpiresAt).Seconds()),
+		RefreshToken:          pair.RefreshToken,
+		RefreshTokenExpiresAt: pair.RefreshTokenExpiresAt,
+	})
+}
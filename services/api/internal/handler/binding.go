@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+)
+
+// ConfigureBinding bindJSON, ShouldBindQueryの設定を変更する。ginの全体の設定のため、routeの登録前に1度のみ呼び出す
+func ConfigureBinding() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("unexpected validator engine: %T", binding.Validator.Engine())
+	}
+	// エラーの項目名をGoのフィールド名ではなく、JSONのキー、クエリパラメータ名とする
+	v.RegisterTagNameFunc(requestFieldName)
+	return nil
+}
+
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// requestJSON binding.JSONと同様に読み込み、項目名の誤りに気付けるよう未定義の項目を含む場合はエラーとする。
+// ginの全体の設定(binding.EnableDecoderDisallowUnknownFields)はrequest以外のJSONの読み込みにも影響するため使用しない
+type requestJSON struct{}
+
+func (requestJSON) Name() string {
+	return "json"
+}
+
+func (requestJSON) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	return decodeRequestJSON(req.Body, obj)
+}
+
+func (requestJSON) BindBody(body []byte, obj any) error {
+	return decodeRequestJSON(bytes.NewReader(body), obj)
+}
+
+func decodeRequestJSON(r io.Reader, obj any) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// bindJSON リクエストボディをrequestのDTOへ読み込み、検証する。エラーはwriteBindingErrorで書き込む
+func bindJSON(c *gin.Context, obj any) error {
+	return c.ShouldBindWith(obj, requestJSON{})
+}
+
+// writeBindingError bindJSONのエラーを、項目ごとのエラーを含む400レスポンスとして書き込む
+func writeBindingError(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, response.NewInvalidRequestError(bindingErrorDetails(err)))
+}
+
+// writeQueryBindingError ShouldBindQueryのエラーを、項目ごとのエラーを含む400レスポンスとして書き込む。
+// objはShouldBindQueryに指定した構造体へのポインタ
+func writeQueryBindingError(c *gin.Context, err error, obj any) {
+	c.JSON(http.StatusBadRequest, response.NewInvalidRequestError(queryBindingErrorDetails(c.Request.URL.Query(), err, obj)))
+}
+
+func bindingErrorDetails(err error) []response.ErrorDetail {
+	var (
+		validationErrs validator.ValidationErrors
+		syntaxErr      *json.SyntaxError
+		typeErr        *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &validationErrs):
+		details := make([]response.ErrorDetail, len(validationErrs))
+		for i, fe := range validationErrs {
+			details[i] = validationErrorDetail(fe)
+		}
+		return details
+	case errors.Is(err, io.EOF):
+		return []response.ErrorDetail{{Rule: "required", Message: "リクエストボディが指定されていません"}}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return []response.ErrorDetail{{Rule: "syntax", Message: "JSONの形式が不正です"}}
+	case errors.As(err, &typeErr):
+		expected := jsonTypeName(typeErr.Type)
+		return []response.ErrorDetail{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   expected,
+			Message: expected + "型で指定してください",
+		}}
+	}
+	// JSONの日時の形式不正は項目名を含まない
+	if detail, ok := conversionErrorDetail("", err); ok {
+		return []response.ErrorDetail{detail}
+	}
+	// encoding/jsonは型のないエラーを返すためメッセージで判定する
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if unquoted, err := strconv.Unquote(field); err == nil {
+			field = unquoted
+		}
+		return []response.ErrorDetail{{Field: field, Rule: "unknown", Message: "未定義の項目です"}}
+	}
+	return []response.ErrorDetail{{Rule: "invalid", Message: "リクエストの形式が不正です"}}
+}
+
+// queryBindingErrorDetails ginのクエリパラメータの変換エラーは項目名を含まないため、
+// パラメータごとに個別に変換し直して失敗した項目を特定する
+func queryBindingErrorDetails(params url.Values, err error, obj any) []response.ErrorDetail {
+	if _, ok := conversionErrorDetail("", err); !ok {
+		return bindingErrorDetails(err)
+	}
+
+	var details []response.ErrorDetail
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		target := reflect.New(reflect.TypeOf(obj).Elem()).Interface()
+		fieldErr := binding.MapFormWithTag(target, map[string][]string{name: params[name]}, "form")
+		if fieldErr == nil {
+			continue
+		}
+		if detail, ok := conversionErrorDetail(name, fieldErr); ok {
+			details = append(details, detail)
+		}
+	}
+	if len(details) == 0 {
+		return bindingErrorDetails(err)
+	}
+	return details
+}
+
+// conversionErrorDetail 日時、数値、真偽値の変換エラーの場合はその詳細を返す
+func conversionErrorDetail(field string, err error) (response.ErrorDetail, bool) {
+	var (
+		timeErr *time.ParseError
+		numErr  *strconv.NumError
+	)
+	switch {
+	case errors.As(err, &timeErr):
+		return response.ErrorDetail{
+			Field:   field,
+			Rule:    "type",
+			Param:   "date-time",
+			Message: "日時はRFC3339形式で指定してください",
+		}, true
+	case errors.As(err, &numErr):
+		return response.ErrorDetail{
+			Field:   field,
+			Rule:    "type",
+			Message: strconv.Quote(numErr.Num) + "を数値、または真偽値として解釈できません",
+		}, true
+	}
+	return response.ErrorDetail{}, false
+}
+
+func validationErrorDetail(fe validator.FieldError) response.ErrorDetail {
+	// Namespaceの先頭はstruct名(CreateUser.email)のため除く
+	_, field, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		field = fe.Field()
+	}
+	return response.ErrorDetail{
+		Field:   field,
+		Rule:    fe.Tag(),
+		Param:   fe.Param(),
+		Message: validationMessage(fe),
+	}
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "必須です"
+	case "min":
+		return rangeMessage(fe, "以上")
+	case "max":
+		return rangeMessage(fe, "以下")
+	case "oneof":
+		return strings.Join(strings.Fields(fe.Param()), ", ") + "のいずれかを指定してください"
+	case "email":
+		return "メールアドレスの形式で指定してください"
+	case "url":
+		return "URLの形式で指定してください"
+	case "uuid":
+		return "UUIDの形式で指定してください"
+	default:
+		return "値が不正です"
+	}
+}
+
+// rangeMessage min, maxのメッセージ。文字列は文字数、配列は要素数の範囲となる
+func rangeMessage(fe validator.FieldError, suffix string) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return fe.Param() + "文字" + suffix + "で指定してください"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fe.Param() + "件" + suffix + "指定してください"
+	default:
+		return fe.Param() + suffix + "で指定してください"
+	}
+}
+
+// jsonTypeName Goの型に対応するJSONの型名
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
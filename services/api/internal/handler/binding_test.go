@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+)
+
+// newBindingTestEngine usecaseを呼び出す前にエラーとなるリクエストのみを扱うため、依存先は不要
+func newBindingTestEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	if err := ConfigureBinding(); err != nil {
+		t.Fatalf("ConfigureBinding: %v", err)
+	}
+
+	h := &Handler{}
+	engine := gin.New()
+	engine.POST("/users", h.CreateUser)
+	engine.GET("/users", h.ListUsers)
+	engine.PATCH("/users/:id", h.UpdateUser)
+	engine.POST("/webhooks", h.CreateWebhook)
+	return engine
+}
+
+func serveBinding(t *testing.T, engine *gin.Engine, method, target, body string) response.Error {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+	}
+	var got response.Error
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid response body %s: %v", rec.Body, err)
+	}
+	if got.Code != "INVALID_REQUEST" {
+		t.Errorf("code = %q, want INVALID_REQUEST", got.Code)
+	}
+	return got
+}
+
+// assertDetails messageを除いて比較する
+func assertDetails(t *testing.T, got, want []response.ErrorDetail) {
+	t.Helper()
+	for i := range got {
+		if got[i].Message == "" {
+			t.Errorf("details[%d].message is empty", i)
+		}
+		got[i].Message = ""
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("details = %+v, want %+v", got, want)
+	}
+}
+
+func TestBindingErrorJSONTypeMismatch(t *testing.T) {
+	engine := newBindingTestEngine(t)
+	got := serveBinding(t, engine, http.MethodPost, "/users", `{"email":123,"username":"alice","password":"password123"}`)
+	assertDetails(t, got.Details, []response.ErrorDetail{
+		{Field: "email", Rule: "type", Param: "string"},
+	})
+}
+
+func TestBindingErrorValidation(t *testing.T) {
+	engine := newBindingTestEngine(t)
+	got := serveBinding(t, engine, http.MethodPost, "/users", `{"email":"not-an-email","username":"al"}`)
+	assertDetails(t, got.Details, []response.ErrorDetail{
+		{Field: "email", Rule: "email"},
+		{Field: "username", Rule: "min", Param: "3"},
+		{Field: "password", Rule: "required"},
+	})
+}
+
+func TestBindingErrorValidationNested(t *testing.T) {
+	engine := newBindingTestEngine(t)
+	body := `{"url":"https://example.com/hook","event_types":["user.created","user.unknown"]}`
+	got := serveBinding(t, engine, http.MethodPost, "/webhooks", body)
+	assertDetails(t, got.Details, []response.ErrorDetail{
+		{Field: "event_types[1]", Rule: "oneof", Param: "user.created user.email_changed user.username_changed user.role_changed user.deleted user.restored"},
+	})
+}
+
+func TestBindingErrorMalformedJSON(t *testing.T) {
+	engine := newBindingTestEngine(t)
+	assertDetails(t, serveBinding(t, engine, http.MethodPost, "/users", `{"email":`).Details, []response.ErrorDetail{
+		{Rule: "syntax"},
+	})
+	assertDetails(t, serveBinding(t, engine, http.MethodPost, "/users", ``).Details, []response.ErrorDetail{
+		{Rule: "required"},
+	})
+}
+
+func TestBindingErrorQuery(t *testing.T) {
+	engine := newBindingTestEngine(t)
+	tests := []struct {
+		name  string
+		query string
+		want  []response.ErrorDetail
+	}{
+		{
+			// 同じ値の別の項目と取り違えない
+			name:  "type",
+			query: "limit=x&offset=x&username_prefix=x",
+			want: []response.ErrorDetail{
+				{Field: "limit", Rule: "type"},
+				{Field: "offset", Rule: "type"},
+			},
+		},
+		{
+			name:  "date-time",
+			query: "created_from=yesterday",
+			want:  []response.ErrorDetail{{Field: "created_from", Rule: "type", Param: "date-time"}},
+		},
+		{
+			name:  "boolean",
+			query: "skip_total=maybe",
+			want:  []response.ErrorDetail{{Field: "skip_total", Rule: "type"}},
+		},
+		{
+			name:  "validation",
+			query: "limit=1000&sort=name",
+			want: []response.ErrorDetail{
+				{Field: "limit", Rule: "max", Param: "100"},
+				{Field: "sort", Rule: "oneof", Param: "created_at updated_at username email"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serveBinding(t, engine, http.MethodGet, "/users?"+tt.query, "")
+			assertDetails(t, got.Details, tt.want)
+		})
+	}
+}
+
+func TestUpdateUserRequiresField(t *testing.T) {
+	engine := newBindingTestEngine(t)
+	got := serveBinding(t, engine, http.MethodPatch, "/users/"+uuid.NewString(), `{}`)
+	assertDetails(t, got.Details, []response.ErrorDetail{{Rule: "required"}})
+}
+
+// 項目名の誤りによる更新漏れに気付けるよう、未定義の項目はエラーとする
+func TestBindingErrorUnknownJSONField(t *testing.T) {
+	engine := newBindingTestEngine(t)
+	got := serveBinding(t, engine, http.MethodPatch, "/users/"+uuid.NewString(), `{"user_name":"alice"}`)
+	assertDetails(t, got.Details, []response.ErrorDetail{{Field: "user_name", Rule: "unknown"}})
+}
+
+// ginの全体の設定は変更せず、他のJSONの読み込みでは未定義の項目を無視する
+func TestConfigureBindingKeepsGlobalDecoder(t *testing.T) {
+	newBindingTestEngine(t)
+	engine := gin.New()
+	engine.POST("/users", func(c *gin.Context) {
+		var req request.CreateUser
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeBindingError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	body := `{"email":"alice@example.com","username":"alice","password":"password123","nickname":"al"}`
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d: %s", rec.Code, http.StatusNoContent, rec.Body)
+	}
+}
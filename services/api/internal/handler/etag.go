@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+)
+
+// setETag ユーザーのversionをETagヘッダーとして設定
+func setETag(c *gin.Context, user *domain.User) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(user.Version())))
+}
+
+// parseIfMatch If-Matchヘッダーに列挙されたversionを取り出す。
+// ヘッダー未指定または"*"の場合はnilを返す。一致し得るETagを含まない場合は412を書き込みfalseを返す
+func parseIfMatch(c *gin.Context) ([]int, bool) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return nil, true
+	}
+
+	var versions []int
+	for tag := range strings.SplitSeq(ifMatch, ",") {
+		// If-Matchは強い比較のため、弱いETag(W/"1")はいずれとも一致しない
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		unquoted, err := strconv.Unquote(tag)
+		if err != nil {
+			unquoted = tag
+		}
+		if version, err := strconv.Atoi(unquoted); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		c.JSON(http.StatusPreconditionFailed, response.NewError("PRECONDITION_FAILED", "If-Matchが現在のリソースと一致しません"))
+		return nil, false
+	}
+	return versions, true
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    []int
+		wantOK  bool
+	}{
+		{"unspecified", "", nil, true},
+		{"any", "*", nil, true},
+		{"strong", `"3"`, []int{3}, true},
+		{"list", `"3", "4"`, []int{3, 4}, true},
+		{"weak is skipped", `W/"3", "4"`, []int{4}, true},
+		{"weak only", `W/"3"`, nil, false},
+		{"not a version", `"abc"`, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/", nil)
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			got, ok := parseIfMatch(c)
+			if ok != tt.wantOK || !slices.Equal(got, tt.want) {
+				t.Errorf("parseIfMatch() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+			if !ok && rec.Code != http.StatusPreconditionFailed {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusPreconditionFailed)
+			}
+		})
+	}
+}
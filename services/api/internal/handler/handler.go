@@ -1,18 +1,49 @@
 package handler
 
 import (
+	"github.com/tokane888/test-mcp/services/api/internal/auth"
+	"github.com/tokane888/test-mcp/services/api/internal/health"
 	"github.com/tokane888/test-mcp/services/api/internal/usecase"
 	"go.uber.org/zap"
 )
 
+// KeySet auth.Managerが満たす
+type KeySet interface {
+	JWKS() auth.JWKS
+}
+
 type Handler struct {
-	logger      *zap.Logger
-	userUseCase usecase.UserUseCase
+	logger               *zap.Logger
+	userUseCase          usecase.UserUseCase
+	passwordResetUseCase usecase.PasswordResetUseCase
+	apiKeyUseCase        usecase.APIKeyUseCase
+	authUseCase          usecase.AuthUseCase
+	auditUseCase         usecase.AuditUseCase
+	webhookUseCase       usecase.WebhookUseCase
+	keySet               KeySet
+	health               *health.Registry
 }
 
-func NewHandler(logger *zap.Logger, userUseCase usecase.UserUseCase) *Handler {
+func NewHandler(
+	logger *zap.Logger,
+	userUseCase usecase.UserUseCase,
+	passwordResetUseCase usecase.PasswordResetUseCase,
+	apiKeyUseCase usecase.APIKeyUseCase,
+	authUseCase usecase.AuthUseCase,
+	auditUseCase usecase.AuditUseCase,
+	webhookUseCase usecase.WebhookUseCase,
+	keySet KeySet,
+	healthRegistry *health.Registry,
+) *Handler {
 	return &Handler{
-		logger:      logger,
-		userUseCase: userUseCase,
+		logger:               logger,
+		userUseCase:          userUseCase,
+		passwordResetUseCase: passwordResetUseCase,
+		apiKeyUseCase:        apiKeyUseCase,
+		authUseCase:          authUseCase,
+		auditUseCase:         auditUseCase,
+		webhookUseCase:       webhookUseCase,
+		keySet:               keySet,
+		health:               healthRegistry,
 	}
 }
@@ -4,10 +4,41 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/health"
+	"go.uber.org/zap"
 )
 
+// Health 後方互換のため残している。依存先の状態は確認しないため、監視には/livez, /readyzを使用すること
 func (h *Handler) Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status": "ok",
 	})
 }
+
+// Livez プロセスが動作しているかを返す。失敗時は再起動の対象となる
+func (h *Handler) Livez(c *gin.Context) {
+	h.writeHealthReport(c, h.health.Liveness(c.Request.Context()))
+}
+
+// Readyz DB等の依存先を含め、リクエストを受け付けられる状態かを返す
+func (h *Handler) Readyz(c *gin.Context) {
+	h.writeHealthReport(c, h.health.Readiness(c.Request.Context()))
+}
+
+func (h *Handler) writeHealthReport(c *gin.Context, report health.Report) {
+	status := http.StatusOK
+	if report.Status != health.StatusPass {
+		status = http.StatusServiceUnavailable
+		for _, check := range report.Checks {
+			if check.Status != health.StatusPass {
+				pkglogger.FromContext(c.Request.Context()).Warn("health check failed",
+					zap.String("path", c.FullPath()),
+					zap.String("check", check.Name),
+					zap.String("error", check.Error),
+				)
+			}
+		}
+	}
+	c.JSON(status, report)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+	"github.com/tokane888/test-mcp/services/api/internal/usecase"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) ChangePassword(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return
+	}
+
+	h.changePassword(c, id)
+}
+
+func (h *Handler) changePassword(c *gin.Context, id uuid.UUID) {
+	var req request.ChangePassword
+	if err := bindJSON(c, &req); err != nil {
+		writeBindingError(c, err)
+		return
+	}
+
+	err := h.userUseCase.ChangePassword(c.Request.Context(), id, &req)
+	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
+		if errors.Is(err, domain.ErrIncorrectPassword) {
+			c.JSON(http.StatusBadRequest, response.NewError("INCORRECT_PASSWORD", "現在のパスワードが正しくありません"))
+			return
+		}
+		if writeDomainError(c, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, response.NewError("USER_NOT_FOUND", "ユーザーが見つかりません"))
+			return
+		}
+		if writeConcurrencyError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to change password", zap.Error(err), zap.String("user_id", id.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) RequestPasswordReset(c *gin.Context) {
+	var req request.RequestPasswordReset
+	if err := bindJSON(c, &req); err != nil {
+		writeBindingError(c, err)
+		return
+	}
+
+	token, expiresAt, err := h.passwordResetUseCase.RequestPasswordReset(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, response.NewError("USER_NOT_FOUND", "ユーザーが見つかりません"))
+			return
+		}
+		if writeForbiddenError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to request password reset", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.PasswordResetToken{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (h *Handler) ConfirmPasswordReset(c *gin.Context) {
+	var req request.ConfirmPasswordReset
+	if err := bindJSON(c, &req); err != nil {
+		writeBindingError(c, err)
+		return
+	}
+
+	err := h.passwordResetUseCase.ConfirmPasswordReset(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, response.NewError("INVALID_RESET_TOKEN", "リセットトークンが無効または期限切れです"))
+			return
+		}
+		if writeDomainError(c, err) {
+			return
+		}
+		if writeForbiddenError(c, err) {
+			return
+		}
+		if writeConcurrencyError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to confirm password reset", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
 	"github.com/tokane888/test-mcp/services/api/internal/domain"
 	"github.com/tokane888/test-mcp/services/api/internal/dto/query"
 	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
@@ -16,61 +17,169 @@ import (
 
 func (h *Handler) CreateUser(c *gin.Context) {
 	var req request.CreateUser
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, response.NewError("INVALID_REQUEST", "リクエストが不正です"))
+	if err := bindJSON(c, &req); err != nil {
+		writeBindingError(c, err)
 		return
 	}
 
 	user, err := h.userUseCase.CreateUser(c.Request.Context(), &req)
 	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
 		// ドメインバリデーションエラーを400エラーにマッピング
-		if errors.Is(err, domain.ErrInvalidEmail) {
-			c.JSON(http.StatusBadRequest, response.NewError("INVALID_EMAIL", "メールアドレスの形式が不正です"))
+		if writeDomainError(c, err) {
 			return
 		}
-		if errors.Is(err, domain.ErrPasswordTooShort) {
-			c.JSON(http.StatusBadRequest, response.NewError("PASSWORD_TOO_SHORT", "パスワードは8文字以上である必要があります"))
+		if errors.Is(err, usecase.ErrUserAlreadyExists) {
+			c.JSON(http.StatusConflict, response.NewError("USER_ALREADY_EXISTS", "ユーザーは既に存在します"))
 			return
 		}
-		if errors.Is(err, domain.ErrUsernameTooShort) {
-			c.JSON(http.StatusBadRequest, response.NewError("USERNAME_TOO_SHORT", "ユーザー名は3文字以上である必要があります"))
+		pkglogger.FromContext(c.Request.Context()).Error("failed to create user", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	setETag(c, user)
+	c.JSON(http.StatusCreated, response.NewUserFromDomain(user))
+}
+
+func (h *Handler) ListUsers(c *gin.Context) {
+	var q query.ListUsers
+	if err := c.ShouldBindQuery(&q); err != nil {
+		writeQueryBindingError(c, err, &q)
+		return
+	}
+
+	list, err := h.userUseCase.ListUsers(c.Request.Context(), &q)
+	if err != nil {
+		if writeForbiddenError(c, err) {
 			return
 		}
-		if errors.Is(err, domain.ErrUsernameTooLong) {
-			c.JSON(http.StatusBadRequest, response.NewError("USERNAME_TOO_LONG", "ユーザー名は100文字以下である必要があります"))
+		if errors.Is(err, usecase.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, response.NewError("INVALID_CURSOR", "カーソルが不正です"))
 			return
 		}
-		if errors.Is(err, domain.ErrInvalidPasswordFormat) {
-			c.JSON(http.StatusBadRequest, response.NewError("INVALID_PASSWORD_FORMAT", "パスワードは英字と数字の両方を含む必要があります"))
+		if errors.Is(err, usecase.ErrInvalidSortField) {
+			c.JSON(http.StatusBadRequest, response.NewError("INVALID_SORT", "ソート項目が不正です"))
 			return
 		}
-		if errors.Is(err, usecase.ErrUserAlreadyExists) {
-			c.JSON(http.StatusConflict, response.NewError("USER_ALREADY_EXISTS", "ユーザーは既に存在します"))
+		pkglogger.FromContext(c.Request.Context()).Error("failed to list users", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewUserListFromDomain(list.Users, list.Total, list.NextCursor))
+}
+
+func (h *Handler) GetUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return
+	}
+
+	user, err := h.userUseCase.GetUser(c.Request.Context(), id)
+	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, response.NewError("USER_NOT_FOUND", "ユーザーが見つかりません"))
 			return
 		}
-		h.logger.Error("failed to create user", zap.Error(err))
+		pkglogger.FromContext(c.Request.Context()).Error("failed to get user", zap.Error(err), zap.String("user_id", id.String()))
 		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
 		return
 	}
 
-	c.JSON(http.StatusCreated, response.NewUserFromDomain(user))
+	setETag(c, user)
+	c.JSON(http.StatusOK, response.NewUserFromDomain(user))
 }
 
-func (h *Handler) ListUsers(c *gin.Context) {
-	var q query.ListUsers
+func (h *Handler) GetUserByEmail(c *gin.Context) {
+	var q query.GetUserByEmail
 	if err := c.ShouldBindQuery(&q); err != nil {
-		c.JSON(http.StatusBadRequest, response.NewError("INVALID_REQUEST", "リクエストが不正です"))
+		writeQueryBindingError(c, err, &q)
 		return
 	}
 
-	users, total, err := h.userUseCase.ListUsers(c.Request.Context(), q.Limit, q.Offset)
+	user, err := h.userUseCase.GetUserByEmail(c.Request.Context(), q.Email)
 	if err != nil {
-		h.logger.Error("failed to list users", zap.Error(err))
+		if writeForbiddenError(c, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, response.NewError("USER_NOT_FOUND", "ユーザーが見つかりません"))
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to get user by email", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
 		return
 	}
 
-	c.JSON(http.StatusOK, response.NewUserListFromDomain(users, total))
+	setETag(c, user)
+	c.JSON(http.StatusOK, response.NewUserFromDomain(user))
+}
+
+func (h *Handler) UpdateUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return
+	}
+
+	h.updateUser(c, id)
+}
+
+func (h *Handler) updateUser(c *gin.Context, id uuid.UUID) {
+	var req request.UpdateUser
+	if err := bindJSON(c, &req); err != nil {
+		writeBindingError(c, err)
+		return
+	}
+	if req.Email == nil && req.Username == nil {
+		c.JSON(http.StatusBadRequest, response.NewInvalidRequestError([]response.ErrorDetail{{
+			Rule:    "required",
+			Message: "email、usernameのいずれかを指定してください",
+		}}))
+		return
+	}
+
+	expectedVersions, ok := parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userUseCase.UpdateUser(c.Request.Context(), id, expectedVersions, &req)
+	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
+		// ドメインバリデーションエラーを400エラーにマッピング
+		if writeDomainError(c, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, response.NewError("USER_NOT_FOUND", "ユーザーが見つかりません"))
+			return
+		}
+		if errors.Is(err, usecase.ErrUserAlreadyExists) {
+			c.JSON(http.StatusConflict, response.NewError("USER_ALREADY_EXISTS", "ユーザーは既に存在します"))
+			return
+		}
+		if writeConcurrencyError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to update user", zap.Error(err), zap.String("user_id", id.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	setETag(c, user)
+	c.JSON(http.StatusOK, response.NewUserFromDomain(user))
 }
 
 func (h *Handler) DeleteUser(c *gin.Context) {
@@ -81,16 +190,185 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	err = h.userUseCase.DeleteUser(c.Request.Context(), id)
+	expectedVersions, ok := parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	err = h.userUseCase.DeleteUser(c.Request.Context(), id, expectedVersions)
 	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
 		if errors.Is(err, usecase.ErrUserNotFound) {
 			c.JSON(http.StatusNotFound, response.NewError("USER_NOT_FOUND", "ユーザーが見つかりません"))
 			return
 		}
-		h.logger.Error("failed to delete user", zap.Error(err), zap.String("user_id", id.String()))
+		if writeConcurrencyError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to delete user", zap.Error(err), zap.String("user_id", id.String()))
 		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+func (h *Handler) RestoreUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return
+	}
+
+	expectedVersions, ok := parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userUseCase.RestoreUser(c.Request.Context(), id, expectedVersions)
+	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, response.NewError("USER_NOT_FOUND", "ユーザーが見つかりません"))
+			return
+		}
+		if errors.Is(err, usecase.ErrUserNotDeleted) {
+			c.JSON(http.StatusConflict, response.NewError("USER_NOT_DELETED", "ユーザーは削除されていません"))
+			return
+		}
+		if errors.Is(err, usecase.ErrUserAlreadyExists) {
+			c.JSON(http.StatusConflict, response.NewError("USER_ALREADY_EXISTS", "ユーザーは既に存在します"))
+			return
+		}
+		if writeConcurrencyError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to restore user", zap.Error(err), zap.String("user_id", id.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	setETag(c, user)
+	c.JSON(http.StatusOK, response.NewUserFromDomain(user))
+}
+
+func (h *Handler) HardDeleteUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return
+	}
+
+	err = h.userUseCase.HardDeleteUser(c.Request.Context(), id)
+	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, response.NewError("USER_NOT_FOUND", "ユーザーが見つかりません"))
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to hard delete user", zap.Error(err), zap.String("user_id", id.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ChangeUserRole(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return
+	}
+
+	var req request.ChangeRole
+	if err := bindJSON(c, &req); err != nil {
+		writeBindingError(c, err)
+		return
+	}
+
+	expectedVersions, ok := parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userUseCase.ChangeRole(c.Request.Context(), id, expectedVersions, domain.Role(req.Role))
+	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrInvalidRole) {
+			c.JSON(http.StatusBadRequest, response.NewError("INVALID_ROLE", "roleが不正です"))
+			return
+		}
+		if errors.Is(err, usecase.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, response.NewError("USER_NOT_FOUND", "ユーザーが見つかりません"))
+			return
+		}
+		if writeConcurrencyError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to change user role", zap.Error(err), zap.String("user_id", id.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	setETag(c, user)
+	c.JSON(http.StatusOK, response.NewUserFromDomain(user))
+}
+
+// writeForbiddenError policyで拒否されたエラーであれば403レスポンスを書き込みtrueを返す
+func writeForbiddenError(c *gin.Context, err error) bool {
+	if errors.Is(err, usecase.ErrForbidden) {
+		c.JSON(http.StatusForbidden, response.NewError("FORBIDDEN", "この操作を行う権限がありません"))
+		return true
+	}
+	return false
+}
+
+// writeDomainError ドメインバリデーションエラーであれば400レスポンスを書き込みtrueを返す
+func writeDomainError(c *gin.Context, err error) bool {
+	if errors.Is(err, domain.ErrInvalidEmail) {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_EMAIL", "メールアドレスの形式が不正です"))
+		return true
+	}
+	if errors.Is(err, domain.ErrPasswordTooShort) {
+		c.JSON(http.StatusBadRequest, response.NewError("PASSWORD_TOO_SHORT", "パスワードは8文字以上である必要があります"))
+		return true
+	}
+	if errors.Is(err, domain.ErrUsernameTooShort) {
+		c.JSON(http.StatusBadRequest, response.NewError("USERNAME_TOO_SHORT", "ユーザー名は3文字以上である必要があります"))
+		return true
+	}
+	if errors.Is(err, domain.ErrUsernameTooLong) {
+		c.JSON(http.StatusBadRequest, response.NewError("USERNAME_TOO_LONG", "ユーザー名は100文字以下である必要があります"))
+		return true
+	}
+	if errors.Is(err, domain.ErrInvalidPasswordFormat) {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_PASSWORD_FORMAT", "パスワードは英字と数字の両方を含む必要があります"))
+		return true
+	}
+	return false
+}
+
+// writeConcurrencyError 楽観的排他制御のエラーであればレスポンスを書き込みtrueを返す
+func writeConcurrencyError(c *gin.Context, err error) bool {
+	if errors.Is(err, usecase.ErrPreconditionFailed) {
+		c.JSON(http.StatusPreconditionFailed, response.NewError("PRECONDITION_FAILED", "If-Matchが現在のリソースと一致しません"))
+		return true
+	}
+	if errors.Is(err, usecase.ErrConflict) {
+		c.JSON(http.StatusConflict, response.NewError("CONFLICT", "他のリクエストによって更新されました。再取得してから再度実行してください"))
+		return true
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/query"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+	"github.com/tokane888/test-mcp/services/api/internal/usecase"
+	"go.uber.org/zap"
+)
+
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	var req request.CreateWebhook
+	if err := bindJSON(c, &req); err != nil {
+		writeBindingError(c, err)
+		return
+	}
+
+	subscription, err := h.webhookUseCase.CreateWebhook(c.Request.Context(), &req)
+	if err != nil {
+		if writeWebhookError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to create webhook", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.CreatedWebhookSubscription{
+		WebhookSubscription: response.NewWebhookSubscriptionFromDomain(subscription),
+		Secret:              subscription.Secret(),
+	})
+}
+
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	subscriptions, err := h.webhookUseCase.ListWebhooks(c.Request.Context())
+	if err != nil {
+		if writeForbiddenError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to list webhooks", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewWebhookSubscriptionListFromDomain(subscriptions))
+}
+
+func (h *Handler) GetWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return
+	}
+
+	subscription, err := h.webhookUseCase.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		if writeWebhookError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to get webhook", zap.Error(err), zap.String("webhook_id", id.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewWebhookSubscriptionFromDomain(subscription))
+}
+
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return
+	}
+
+	var req request.UpdateWebhook
+	if err := bindJSON(c, &req); err != nil {
+		writeBindingError(c, err)
+		return
+	}
+
+	subscription, err := h.webhookUseCase.UpdateWebhook(c.Request.Context(), id, &req)
+	if err != nil {
+		if writeWebhookError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to update webhook", zap.Error(err), zap.String("webhook_id", id.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewWebhookSubscriptionFromDomain(subscription))
+}
+
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return
+	}
+
+	if err := h.webhookUseCase.DeleteWebhook(c.Request.Context(), id); err != nil {
+		if writeWebhookError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to delete webhook", zap.Error(err), zap.String("webhook_id", id.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) ListWebhookDeliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return
+	}
+
+	var q query.ListWebhookDeliveries
+	if err := c.ShouldBindQuery(&q); err != nil {
+		writeQueryBindingError(c, err, &q)
+		return
+	}
+
+	list, err := h.webhookUseCase.ListDeliveries(c.Request.Context(), id, &q)
+	if err != nil {
+		if writeWebhookError(c, err) {
+			return
+		}
+		if errors.Is(err, usecase.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, response.NewError("INVALID_CURSOR", "カーソルが不正です"))
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to list webhook deliveries", zap.Error(err), zap.String("webhook_id", id.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewWebhookDeliveryListFromDomain(list.Deliveries, list.NextCursor))
+}
+
+func (h *Handler) GetWebhookDelivery(c *gin.Context) {
+	id, deliveryID, ok := parseWebhookDeliveryIDs(c)
+	if !ok {
+		return
+	}
+
+	detail, err := h.webhookUseCase.GetDelivery(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		if writeWebhookError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to get webhook delivery", zap.Error(err), zap.String("delivery_id", deliveryID.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NewWebhookDeliveryDetailFromDomain(detail.Delivery, detail.Attempts))
+}
+
+// ReplayWebhookDelivery 再送はwebhook-dispatcherが非同期に行うため202を返す
+func (h *Handler) ReplayWebhookDelivery(c *gin.Context) {
+	id, deliveryID, ok := parseWebhookDeliveryIDs(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookUseCase.ReplayDelivery(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		if writeWebhookError(c, err) {
+			return
+		}
+		pkglogger.FromContext(c.Request.Context()).Error("failed to replay webhook delivery", zap.Error(err), zap.String("delivery_id", deliveryID.String()))
+		c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.NewWebhookDeliveryFromDomain(delivery))
+}
+
+func parseWebhookDeliveryIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_ID", "IDの形式が不正です"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return id, deliveryID, true
+}
+
+// writeWebhookError webhook操作に共通するエラーのレスポンスを返す。対応するエラーでない場合はfalse
+func writeWebhookError(c *gin.Context, err error) bool {
+	switch {
+	case writeForbiddenError(c, err):
+	case errors.Is(err, domain.ErrWebhookURLNotPublic):
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_WEBHOOK_URL", "ループバック、プライベート等の内部のアドレスは指定できません"))
+	case errors.Is(err, domain.ErrInvalidWebhookURL):
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_WEBHOOK_URL", "URLはhttpまたはhttpsの絶対URLで指定してください"))
+	case errors.Is(err, domain.ErrInvalidWebhookEventType), errors.Is(err, domain.ErrWebhookEventTypesEmpty):
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_EVENT_TYPE", "イベント種別が不正です"))
+	case errors.Is(err, usecase.ErrInvalidWebhookRequest):
+		c.JSON(http.StatusBadRequest, response.NewError("INVALID_REQUEST", "リクエストが不正です"))
+	case errors.Is(err, usecase.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, response.NewError("WEBHOOK_NOT_FOUND", "webhookが見つかりません"))
+	case errors.Is(err, usecase.ErrWebhookDeliveryNotFound):
+		c.JSON(http.StatusNotFound, response.NewError("WEBHOOK_DELIVERY_NOT_FOUND", "配信が見つかりません"))
+	case errors.Is(err, usecase.ErrWebhookDeliveryPending):
+		c.JSON(http.StatusConflict, response.NewError("WEBHOOK_DELIVERY_PENDING", "配信待ちの配信は再送できません"))
+	case writeConcurrencyError(c, err):
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DatabaseCheck DBへpingできることを確認する
+func DatabaseCheck(db *sql.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if err := db.PingContext(ctx); err != nil {
+			return fmt.Errorf("failed to ping database: %w", err)
+		}
+		return nil
+	})
+}
+
+// MigrationVersioner *migration.Migratorが満たす
+type MigrationVersioner interface {
+	Version(ctx context.Context) (int64, error)
+	Latest() int64
+}
+
+// MigrationCheck 組み込まれている最新のmigrationまで適用済みであることを確認する
+func MigrationCheck(migrator MigrationVersioner) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		current, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		if latest := migrator.Latest(); current < latest {
+			return fmt.Errorf("database schema is outdated: applied version %d, expected %d", current, latest)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,158 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+)
+
+// Checker 依存先等の状態を確認する。異常な場合はerrorを返す
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc 関数をCheckerとして扱うためのadapter
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckResult 1件のcheckの結果
+type CheckResult struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	// 失敗の詳細。DBの接続先等を含み得るため、レスポンスには含めずログにのみ出力する
+	Error     string  `json:"-"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// Report 全checkの結果。1件でも失敗した場合StatusはStatusFail
+type Report struct {
+	Status Status        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+type namedChecker struct {
+	name    string
+	checker Checker
+}
+
+// Registry liveness/readiness向けのcheckを保持し、実行する
+type Registry struct {
+	timeout time.Duration
+
+	mu        sync.RWMutex
+	liveness  []namedChecker
+	readiness []namedChecker
+
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry timeoutは各checkの実行時間上限。0の場合は無制限
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+// AddLivenessCheck プロセス自体が正常に動作しているかのcheckを追加する。
+// 失敗するとプロセスが再起動されるため、外部の依存先のcheckは追加しないこと
+func (r *Registry) AddLivenessCheck(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liveness = append(r.liveness, namedChecker{name: name, checker: checker})
+}
+
+// AddReadinessCheck リクエストを受け付けられる状態かのcheckを追加する
+func (r *Registry) AddReadinessCheck(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readiness = append(r.readiness, namedChecker{name: name, checker: checker})
+}
+
+// SetShuttingDown graceful shutdownの開始を通知する。以降readinessは常に失敗する
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Liveness liveness向けのcheckを全て実行する
+func (r *Registry) Liveness(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := append([]namedChecker(nil), r.liveness...)
+	r.mu.RUnlock()
+	return r.run(ctx, checkers)
+}
+
+// Readiness readiness向けのcheckを全て実行する
+func (r *Registry) Readiness(ctx context.Context) Report {
+	r.mu.RLock()
+	checkers := append([]namedChecker(nil), r.readiness...)
+	r.mu.RUnlock()
+
+	// shutdown中は依存先の状態に関わらず新規リクエストの振り分け対象から外す
+	checkers = append([]namedChecker{{name: "shutdown", checker: CheckerFunc(r.checkShutdown)}}, checkers...)
+	return r.run(ctx, checkers)
+}
+
+func (r *Registry) checkShutdown(context.Context) error {
+	if r.shuttingDown.Load() {
+		return fmt.Errorf("server is shutting down")
+	}
+	return nil
+}
+
+// run checkを並行して実行し、登録順に結果を返す
+func (r *Registry) run(ctx context.Context, checkers []namedChecker) Report {
+	results := make([]CheckResult, len(checkers))
+	var wg sync.WaitGroup
+	for i, nc := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.runOne(ctx, nc)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusPass, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusPass {
+			report.Status = StatusFail
+			break
+		}
+	}
+	return report
+}
+
+func (r *Registry) runOne(ctx context.Context, nc namedChecker) (result CheckResult) {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	result = CheckResult{Name: nc.name, Status: StatusPass}
+	start := time.Now()
+	defer func() {
+		// checkのpanicでプロセス全体が停止しないよう失敗として扱う
+		if p := recover(); p != nil {
+			result.Status = StatusFail
+			result.Error = fmt.Sprintf("panic: %v", p)
+		}
+		result.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	}()
+
+	if err := nc.checker.Check(ctx); err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tokane888/test-mcp/services/api/internal/health"
+)
+
+func TestReadinessHidesCheckError(t *testing.T) {
+	registry := health.NewRegistry(0)
+	registry.AddReadinessCheck("database", health.CheckerFunc(func(context.Context) error {
+		return errors.New("dial tcp 10.0.0.5:5432: connect: connection refused")
+	}))
+
+	report := registry.Readiness(context.Background())
+	if report.Status != health.StatusFail {
+		t.Fatalf("Status = %q, want %q", report.Status, health.StatusFail)
+	}
+	// ログ出力向けに詳細は保持する
+	if got := report.Checks[1].Error; !strings.Contains(got, "10.0.0.5") {
+		t.Errorf("Checks[1].Error = %q, want the check error", got)
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if strings.Contains(string(body), "10.0.0.5") || strings.Contains(string(body), `"error"`) {
+		t.Errorf("response body exposes check error: %s", body)
+	}
+}
@@ -0,0 +1,148 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+)
+
+type apiKeyRepositoryImpl struct {
+	mu   sync.RWMutex
+	keys map[uuid.UUID]*domain.APIKey
+}
+
+func NewAPIKeyRepository() repository.APIKeyRepository {
+	return &apiKeyRepositoryImpl{
+		keys: make(map[uuid.UUID]*domain.APIKey),
+	}
+}
+
+func (r *apiKeyRepositoryImpl) snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 保存済みの値は更新時に置き換えるため、複製は浅いもので足りる
+	saved := maps.Clone(r.keys)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.keys = saved
+	}
+}
+
+func (r *apiKeyRepositoryImpl) Create(_ context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[key.ID()]; ok {
+		return fmt.Errorf("failed to create api key: %w", repository.ErrUniqueViolation)
+	}
+	for _, k := range r.keys {
+		if k.KeyHash() == key.KeyHash() {
+			return fmt.Errorf("%w: %w", repository.ErrAPIKeyAlreadyExists, repository.ErrUniqueViolation)
+		}
+	}
+	r.keys[key.ID()] = copyAPIKey(key)
+	return nil
+}
+
+func (r *apiKeyRepositoryImpl) FindByID(_ context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, repository.ErrAPIKeyNotFound
+	}
+	return copyAPIKey(key), nil
+}
+
+func (r *apiKeyRepositoryImpl) FindByKeyHash(_ context.Context, keyHash string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.KeyHash() == keyHash {
+			return copyAPIKey(key), nil
+		}
+	}
+	return nil, repository.ErrAPIKeyNotFound
+}
+
+func (r *apiKeyRepositoryImpl) List(_ context.Context) ([]*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*domain.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, copyAPIKey(key))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt().Equal(keys[j].CreatedAt()) {
+			return keys[i].CreatedAt().After(keys[j].CreatedAt())
+		}
+		return keys[i].ID().String() > keys[j].ID().String()
+	})
+	return keys, nil
+}
+
+func (r *apiKeyRepositoryImpl) UpdateRevokedAt(_ context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.keys[key.ID()]
+	if !ok {
+		return repository.ErrAPIKeyNotFound
+	}
+	r.keys[key.ID()] = domain.ReconstructAPIKey(
+		stored.ID(),
+		stored.Name(),
+		stored.Owner(),
+		stored.Prefix(),
+		stored.KeyHash(),
+		stored.Scopes(),
+		stored.Role(),
+		stored.ExpiresAt(),
+		stored.LastUsedAt(),
+		key.RevokedAt(),
+		stored.CreatedAt(),
+	)
+	return nil
+}
+
+func (r *apiKeyRepositoryImpl) UpdateLastUsedAt(_ context.Context, id uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.keys[id]
+	if !ok {
+		return nil
+	}
+	if last := stored.LastUsedAt(); last == nil || last.Before(at) {
+		stored.MarkUsed(at)
+	}
+	return nil
+}
+
+func copyAPIKey(key *domain.APIKey) *domain.APIKey {
+	return domain.ReconstructAPIKey(
+		key.ID(),
+		key.Name(),
+		key.Owner(),
+		key.Prefix(),
+		key.KeyHash(),
+		key.Scopes(),
+		key.Role(),
+		key.ExpiresAt(),
+		key.LastUsedAt(),
+		key.RevokedAt(),
+		key.CreatedAt(),
+	)
+}
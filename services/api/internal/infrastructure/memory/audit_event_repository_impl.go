@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+)
+
+// auditEventRepositoryImpl 監査ログは追記のみで、AuditEventは生成後に変更されないため複製せずに保持する
+type auditEventRepositoryImpl struct {
+	mu     sync.RWMutex
+	events []*domain.AuditEvent
+}
+
+func NewAuditEventRepository() repository.AuditEventRepository {
+	return &auditEventRepositoryImpl{}
+}
+
+func (r *auditEventRepositoryImpl) snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 保存済みの値は更新時に置き換えるため、複製は浅いもので足りる
+	saved := slices.Clone(r.events)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = saved
+	}
+}
+
+func (r *auditEventRepositoryImpl) Create(_ context.Context, event *domain.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *auditEventRepositoryImpl) List(_ context.Context, params repository.AuditEventListParams) (*repository.AuditEventListResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*domain.AuditEvent
+	for _, event := range r.events {
+		if matchAuditEvent(event, params.Filter) && isAfterAuditCursor(event, params.After) {
+			matched = append(matched, event)
+		}
+	}
+	sortAuditEvents(matched)
+
+	result := &repository.AuditEventListResult{}
+	if len(matched) > params.Limit {
+		matched = matched[:params.Limit]
+		last := matched[len(matched)-1]
+		result.NextCursor = &repository.AuditEventCursor{OccurredAt: last.OccurredAt(), ID: last.ID()}
+	}
+	result.Events = matched
+	return result, nil
+}
+
+func matchAuditEvent(event *domain.AuditEvent, filter repository.AuditEventFilter) bool {
+	if filter.ActorType != "" && event.Actor().Type != filter.ActorType {
+		return false
+	}
+	if filter.ActorID != "" && event.Actor().ID != filter.ActorID {
+		return false
+	}
+	if filter.Action != "" && event.Action() != filter.Action {
+		return false
+	}
+	if filter.TargetUserID != uuid.Nil && event.TargetUserID() != filter.TargetUserID {
+		return false
+	}
+	if filter.From != nil && event.OccurredAt().Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && !event.OccurredAt().Before(*filter.To) {
+		return false
+	}
+	return true
+}
+
+// isAfterAuditCursor (occurred_at, id)の組がカーソルより小さい場合にtrueを返す
+func isAfterAuditCursor(event *domain.AuditEvent, cursor *repository.AuditEventCursor) bool {
+	if cursor == nil {
+		return true
+	}
+	return compareAuditEvent(event, cursor) < 0
+}
+
+func compareAuditEvent(event *domain.AuditEvent, cursor *repository.AuditEventCursor) int {
+	if c := event.OccurredAt().Compare(cursor.OccurredAt); c != 0 {
+		return c
+	}
+	return compareUUID(event.ID(), cursor.ID)
+}
+
+func sortAuditEvents(events []*domain.AuditEvent) {
+	slices.SortStableFunc(events, func(a, b *domain.AuditEvent) int {
+		return -compareAuditEvent(a, &repository.AuditEventCursor{OccurredAt: b.OccurredAt(), ID: b.ID()})
+	})
+}
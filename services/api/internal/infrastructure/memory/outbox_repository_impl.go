@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+)
+
+// outboxRepositoryImpl outbox-relayはPostgreSQLのみに対応するため、保存したイベントは配信されない
+type outboxRepositoryImpl struct {
+	mu     sync.Mutex
+	events []domain.Event
+}
+
+func NewOutboxRepository() repository.OutboxRepository {
+	return &outboxRepositoryImpl{}
+}
+
+func (r *outboxRepositoryImpl) snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 保存済みの値は更新時に置き換えるため、複製は浅いもので足りる
+	saved := slices.Clone(r.events)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = saved
+	}
+}
+
+func (r *outboxRepositoryImpl) Append(_ context.Context, events []domain.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, events...)
+	return nil
+}
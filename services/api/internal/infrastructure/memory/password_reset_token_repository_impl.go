@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+)
+
+type passwordResetTokenRepositoryImpl struct {
+	mu     sync.Mutex
+	tokens map[uuid.UUID]*domain.PasswordResetToken
+}
+
+func NewPasswordResetTokenRepository() repository.PasswordResetTokenRepository {
+	return &passwordResetTokenRepositoryImpl{
+		tokens: make(map[uuid.UUID]*domain.PasswordResetToken),
+	}
+}
+
+func (r *passwordResetTokenRepositoryImpl) snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 保存済みの値は更新時に置き換えるため、複製は浅いもので足りる
+	saved := maps.Clone(r.tokens)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.tokens = saved
+	}
+}
+
+func (r *passwordResetTokenRepositoryImpl) Create(_ context.Context, token *domain.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[token.ID()] = copyToken(token)
+	return nil
+}
+
+func (r *passwordResetTokenRepositoryImpl) FindByTokenHash(_ context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash() == tokenHash {
+			return copyToken(token), nil
+		}
+	}
+	return nil, repository.ErrResetTokenNotFound
+}
+
+func (r *passwordResetTokenRepositoryImpl) MarkUsed(_ context.Context, token *domain.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tokens[token.ID()]
+	if !ok || stored.UsedAt() != nil {
+		return repository.ErrResetTokenNotFound
+	}
+	r.tokens[token.ID()] = copyToken(token)
+	return nil
+}
+
+func copyToken(token *domain.PasswordResetToken) *domain.PasswordResetToken {
+	return domain.ReconstructPasswordResetToken(
+		token.ID(),
+		token.UserID(),
+		token.TokenHash(),
+		token.ExpiresAt(),
+		token.UsedAt(),
+		token.CreatedAt(),
+	)
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+)
+
+type refreshTokenRepositoryImpl struct {
+	mu     sync.Mutex
+	tokens map[uuid.UUID]*domain.RefreshToken
+}
+
+func NewRefreshTokenRepository() repository.RefreshTokenRepository {
+	return &refreshTokenRepositoryImpl{
+		tokens: make(map[uuid.UUID]*domain.RefreshToken),
+	}
+}
+
+func (r *refreshTokenRepositoryImpl) snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 保存済みの値は更新時に置き換えるため、複製は浅いもので足りる
+	saved := maps.Clone(r.tokens)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.tokens = saved
+	}
+}
+
+func (r *refreshTokenRepositoryImpl) Create(_ context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[token.ID()] = copyRefreshToken(token)
+	return nil
+}
+
+func (r *refreshTokenRepositoryImpl) FindByTokenHash(_ context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash() == tokenHash {
+			return copyRefreshToken(token), nil
+		}
+	}
+	return nil, repository.ErrRefreshTokenNotFound
+}
+
+func (r *refreshTokenRepositoryImpl) MarkUsed(_ context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tokens[token.ID()]
+	if !ok {
+		return repository.ErrRefreshTokenNotFound
+	}
+	if stored.UsedAt() != nil {
+		return repository.ErrRefreshTokenAlreadyUsed
+	}
+	r.tokens[token.ID()] = domain.ReconstructRefreshToken(
+		stored.ID(),
+		stored.UserID(),
+		stored.FamilyID(),
+		stored.TokenHash(),
+		stored.ExpiresAt(),
+		token.UsedAt(),
+		stored.RevokedAt(),
+		stored.CreatedAt(),
+	)
+	return nil
+}
+
+func (r *refreshTokenRepositoryImpl) RevokeFamily(_ context.Context, familyID uuid.UUID, at time.Time) error {
+	r.revokeWhere(func(token *domain.RefreshToken) bool { return token.FamilyID() == familyID }, at)
+	return nil
+}
+
+func (r *refreshTokenRepositoryImpl) RevokeAllForUser(_ context.Context, userID uuid.UUID, at time.Time) error {
+	r.revokeWhere(func(token *domain.RefreshToken) bool { return token.UserID() == userID }, at)
+	return nil
+}
+
+func (r *refreshTokenRepositoryImpl) revokeWhere(match func(*domain.RefreshToken) bool, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if !match(token) || token.RevokedAt() != nil {
+			continue
+		}
+		r.tokens[id] = domain.ReconstructRefreshToken(
+			token.ID(),
+			token.UserID(),
+			token.FamilyID(),
+			token.TokenHash(),
+			token.ExpiresAt(),
+			token.UsedAt(),
+			&at,
+			token.CreatedAt(),
+		)
+	}
+}
+
+func copyRefreshToken(token *domain.RefreshToken) *domain.RefreshToken {
+	return domain.ReconstructRefreshToken(
+		token.ID(),
+		token.UserID(),
+		token.FamilyID(),
+		token.TokenHash(),
+		token.ExpiresAt(),
+		token.UsedAt(),
+		token.RevokedAt(),
+		token.CreatedAt(),
+	)
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+)
+
+type txKey struct{}
+
+// txParticipant rollbackの対象となるrepository
+type txParticipant interface {
+	// snapshot 現在の状態を保存し、その状態に戻す関数を返す
+	snapshot() (restore func())
+}
+
+// txManagerImpl メモリ上の実装向けのTxManager。
+// トランザクション同士を直列化し、fnがエラーを返した場合は開始時点の状態に戻す。
+// トランザクション外の書き込み(API keyの最終使用日時の更新等)が並行した場合、その内容もrollbackで失われる
+type txManagerImpl struct {
+	mu           sync.Mutex
+	participants []txParticipant
+}
+
+// NewTxManager repositoriesのうち、このpackageで実装したものをrollbackの対象とする
+func NewTxManager(repositories ...any) repository.TxManager {
+	m := &txManagerImpl{}
+	for _, repo := range repositories {
+		if p, ok := repo.(txParticipant); ok {
+			m.participants = append(m.participants, p)
+		}
+	}
+	return m
+}
+
+func (m *txManagerImpl) WithinTx(ctx context.Context, fn func(ctx context.Context) error, _ ...repository.TxOption) error {
+	// 既にトランザクション内であれば外側のトランザクションに参加する
+	if _, ok := ctx.Value(txKey{}).(bool); ok {
+		return fn(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	restores := make([]func(), 0, len(m.participants))
+	for _, p := range m.participants {
+		restores = append(restores, p.snapshot())
+	}
+	if err := fn(context.WithValue(ctx, txKey{}, true)); err != nil {
+		for _, restore := range slices.Backward(restores) {
+			restore()
+		}
+		return err
+	}
+	return nil
+}
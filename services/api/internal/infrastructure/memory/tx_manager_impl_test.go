@@ -0,0 +1,74 @@
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/infrastructure/memory"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+)
+
+func TestTxManagerRollback(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepository()
+	tokens := memory.NewPasswordResetTokenRepository()
+	txManager := memory.NewTxManager(users, tokens)
+
+	kept, err := domain.NewUser("kept@example.com", "kept", "password123")
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	if err := users.Create(ctx, kept); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	discarded, err := domain.NewUser("discarded@example.com", "discarded", "password123")
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	errAbort := errors.New("abort")
+	err = txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := users.Create(ctx, discarded); err != nil {
+			return err
+		}
+		if err := users.HardDelete(ctx, kept.ID()); err != nil {
+			return err
+		}
+		// 入れ子のトランザクションは外側と合わせてrollbackされる
+		return txManager.WithinTx(ctx, func(ctx context.Context) error {
+			return errAbort
+		})
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("WithinTx error = %v, want %v", err, errAbort)
+	}
+
+	if _, err := users.FindByID(ctx, discarded.ID()); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("user created in rolled back tx: FindByID error = %v, want %v", err, repository.ErrUserNotFound)
+	}
+	if _, err := users.FindByID(ctx, kept.ID()); err != nil {
+		t.Errorf("user deleted in rolled back tx: FindByID error = %v, want nil", err)
+	}
+}
+
+func TestTxManagerCommit(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepository()
+	txManager := memory.NewTxManager(users)
+
+	user, err := domain.NewUser("alice@example.com", "alice", "password123")
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	err = txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return users.Create(ctx, user)
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+	if _, err := users.FindByID(ctx, user.ID()); err != nil {
+		t.Errorf("FindByID error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,327 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+)
+
+// userRepositoryImpl repository.UserRepositoryのメモリ上の実装。
+// テストやDBを用意しないローカル実行向けで、PostgreSQL実装と同じ振る舞いとする。
+// 文字列のソート順のみ、DBのcollationではなくバイト順となる
+type userRepositoryImpl struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]*domain.User
+}
+
+func NewUserRepository() repository.UserRepository {
+	return &userRepositoryImpl{
+		users: make(map[uuid.UUID]*domain.User),
+	}
+}
+
+func (r *userRepositoryImpl) snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 保存済みの値は更新時に置き換えるため、複製は浅いもので足りる
+	saved := maps.Clone(r.users)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.users = saved
+	}
+}
+
+func (r *userRepositoryImpl) Create(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID()]; ok {
+		return fmt.Errorf("failed to create user: %w", repository.ErrUniqueViolation)
+	}
+	if r.emailTaken(user.Email(), user.ID()) {
+		return repository.ErrUserAlreadyExists
+	}
+
+	r.users[user.ID()] = copyUser(user)
+	return nil
+}
+
+func (r *userRepositoryImpl) FindByID(_ context.Context, id uuid.UUID) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok || user.IsDeleted() {
+		return nil, repository.ErrUserNotFound
+	}
+	return copyUser(user), nil
+}
+
+func (r *userRepositoryImpl) FindByIDIncludingDeleted(_ context.Context, id uuid.UUID) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	return copyUser(user), nil
+}
+
+func (r *userRepositoryImpl) FindByEmail(_ context.Context, email string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if !user.IsDeleted() && strings.EqualFold(user.Email(), email) {
+			return copyUser(user), nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *userRepositoryImpl) List(_ context.Context, params repository.UserListParams) (*repository.UserListResult, error) {
+	less, ok := userLessFuncs[params.Sort.Field]
+	if !ok {
+		return nil, repository.ErrInvalidSortField
+	}
+	// (ソート項目, id)の組で比較する。DescならSQLのORDER BY ... DESC, id DESCと同じ順序になる
+	before := func(a, b *domain.User) bool {
+		if params.Sort.Desc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return compareUUID(a.ID(), b.ID()) < 0
+	}
+
+	r.mu.RLock()
+	var matched []*domain.User
+	for _, user := range r.users {
+		if matchesFilter(user, params.Filter) {
+			matched = append(matched, copyUser(user))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		return before(matched[i], matched[j])
+	})
+
+	result := &repository.UserListResult{}
+	if !params.SkipTotal {
+		total := len(matched)
+		result.Total = &total
+	}
+
+	var page []*domain.User
+	if params.After != nil {
+		for i, user := range matched {
+			if cursorBefore(params.After, user, params.Sort) {
+				page = matched[i:]
+				break
+			}
+		}
+	} else if params.Offset < len(matched) {
+		page = matched[params.Offset:]
+	}
+
+	if len(page) > params.Limit {
+		page = page[:params.Limit]
+		last := page[len(page)-1]
+		result.NextCursor = &repository.UserCursor{
+			Value: userSortValue(last, params.Sort.Field),
+			ID:    last.ID(),
+		}
+	}
+	result.Users = page
+
+	return result, nil
+}
+
+func (r *userRepositoryImpl) Update(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[user.ID()]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	if stored.Version() != user.Version() {
+		return repository.ErrConflict
+	}
+	if !user.IsDeleted() && r.emailTaken(user.Email(), user.ID()) {
+		return repository.ErrUserAlreadyExists
+	}
+
+	// PostgreSQL実装と同じくpassword_hashは更新しない
+	updated := domain.ReconstructUser(
+		user.ID(),
+		user.Email(),
+		user.Username(),
+		stored.PasswordHash(),
+		user.Role(),
+		stored.CreatedAt(),
+		user.UpdatedAt(),
+		user.DeletedAt(),
+		stored.Version()+1,
+	)
+	r.users[user.ID()] = updated
+	user.IncrementVersion()
+	return nil
+}
+
+func (r *userRepositoryImpl) UpdatePassword(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[user.ID()]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	if stored.IsDeleted() || stored.Version() != user.Version() {
+		return repository.ErrConflict
+	}
+
+	updated := domain.ReconstructUser(
+		stored.ID(),
+		stored.Email(),
+		stored.Username(),
+		user.PasswordHash(),
+		stored.Role(),
+		stored.CreatedAt(),
+		user.UpdatedAt(),
+		stored.DeletedAt(),
+		stored.Version()+1,
+	)
+	r.users[user.ID()] = updated
+	user.IncrementVersion()
+	return nil
+}
+
+func (r *userRepositoryImpl) ExistsByEmail(_ context.Context, email string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.emailTaken(email, uuid.Nil), nil
+}
+
+func (r *userRepositoryImpl) HardDelete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return repository.ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+// emailTaken exclude以外の有効なユーザーが同じメールアドレス(大文字小文字を区別しない)を使用しているか。
+// 呼び出し元でロックを取得していること
+func (r *userRepositoryImpl) emailTaken(email string, exclude uuid.UUID) bool {
+	for id, user := range r.users {
+		if id != exclude && !user.IsDeleted() && strings.EqualFold(user.Email(), email) {
+			return true
+		}
+	}
+	return false
+}
+
+var userLessFuncs = map[repository.UserSortField]func(a, b *domain.User) bool{
+	repository.UserSortByCreatedAt: func(a, b *domain.User) bool { return a.CreatedAt().Before(b.CreatedAt()) },
+	repository.UserSortByUpdatedAt: func(a, b *domain.User) bool { return a.UpdatedAt().Before(b.UpdatedAt()) },
+	repository.UserSortByUsername:  func(a, b *domain.User) bool { return a.Username() < b.Username() },
+	repository.UserSortByEmail:     func(a, b *domain.User) bool { return a.Email() < b.Email() },
+}
+
+func userSortValue(user *domain.User, field repository.UserSortField) any {
+	switch field {
+	case repository.UserSortByUpdatedAt:
+		return user.UpdatedAt()
+	case repository.UserSortByUsername:
+		return user.Username()
+	case repository.UserSortByEmail:
+		return user.Email()
+	default:
+		return user.CreatedAt()
+	}
+}
+
+// cursorBefore カーソル位置がuserより前か(userがカーソルより後ろの行か)
+func cursorBefore(cursor *repository.UserCursor, user *domain.User, sort repository.UserSort) bool {
+	var cmp int
+	switch v := cursor.Value.(type) {
+	case time.Time:
+		cmp = v.Compare(userSortValue(user, sort.Field).(time.Time))
+	case string:
+		cmp = strings.Compare(v, userSortValue(user, sort.Field).(string))
+	}
+	if cmp == 0 {
+		cmp = compareUUID(cursor.ID, user.ID())
+	}
+	if sort.Desc {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+func matchesFilter(user *domain.User, filter repository.UserFilter) bool {
+	if !filter.IncludeDeleted && user.IsDeleted() {
+		return false
+	}
+	if filter.EmailDomain != "" {
+		_, domainPart, _ := strings.Cut(user.Email(), "@")
+		if !strings.EqualFold(domainPart, filter.EmailDomain) {
+			return false
+		}
+	}
+	username := strings.ToLower(user.Username())
+	if filter.UsernamePrefix != "" && !strings.HasPrefix(username, strings.ToLower(filter.UsernamePrefix)) {
+		return false
+	}
+	if filter.UsernameContains != "" && !strings.Contains(username, strings.ToLower(filter.UsernameContains)) {
+		return false
+	}
+	if filter.CreatedFrom != nil && user.CreatedAt().Before(*filter.CreatedFrom) {
+		return false
+	}
+	if filter.CreatedTo != nil && !user.CreatedAt().Before(*filter.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// compareUUID PostgreSQLのuuid型と同じくバイト列として比較
+func compareUUID(a, b uuid.UUID) int {
+	return strings.Compare(string(a[:]), string(b[:]))
+}
+
+// copyUser 呼び出し元による変更が保存済みのデータに影響しないよう複製する
+func copyUser(user *domain.User) *domain.User {
+	return domain.ReconstructUser(
+		user.ID(),
+		user.Email(),
+		user.Username(),
+		user.PasswordHash(),
+		user.Role(),
+		user.CreatedAt(),
+		user.UpdatedAt(),
+		user.DeletedAt(),
+		user.Version(),
+	)
+}
@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/tokane888/test-mcp/services/api/internal/infrastructure/memory"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"github.com/tokane888/test-mcp/services/api/internal/repository/repositorytest"
+)
+
+func TestUserRepository(t *testing.T) {
+	repositorytest.RunUserRepositoryTests(t, func(t *testing.T) repository.UserRepository {
+		return memory.NewUserRepository()
+	})
+}
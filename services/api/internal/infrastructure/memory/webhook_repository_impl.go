@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+)
+
+type webhookSubscriptionRepositoryImpl struct {
+	mu            sync.RWMutex
+	subscriptions map[uuid.UUID]*domain.WebhookSubscription
+}
+
+func NewWebhookSubscriptionRepository() repository.WebhookSubscriptionRepository {
+	return &webhookSubscriptionRepositoryImpl{
+		subscriptions: make(map[uuid.UUID]*domain.WebhookSubscription),
+	}
+}
+
+func (r *webhookSubscriptionRepositoryImpl) snapshot() func() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 保存済みの値は更新時に置き換えるため、複製は浅いもので足りる
+	saved := maps.Clone(r.subscriptions)
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.subscriptions = saved
+	}
+}
+
+func (r *webhookSubscriptionRepositoryImpl) Create(_ context.Context, subscription *domain.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions[subscription.ID()] = copyWebhookSubscription(subscription)
+	return nil
+}
+
+func (r *webhookSubscriptionRepositoryImpl) FindByID(_ context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return nil, repository.ErrWebhookNotFound
+	}
+	return copyWebhookSubscription(subscription), nil
+}
+
+func (r *webhookSubscriptionRepositoryImpl) List(_ context.Context) ([]*domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscriptions := make([]*domain.WebhookSubscription, 0, len(r.subscriptions))
+	for _, subscription := range r.subscriptions {
+		subscriptions = append(subscriptions, copyWebhookSubscription(subscription))
+	}
+	slices.SortFunc(subscriptions, func(a, b *domain.WebhookSubscription) int {
+		if c := b.CreatedAt().Compare(a.CreatedAt()); c != 0 {
+			return c
+		}
+		return compareUUID(b.ID(), a.ID())
+	})
+	return subscriptions, nil
+}
+
+func (r *webhookSubscriptionRepositoryImpl) Update(_ context.Context, subscription *domain.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[subscription.ID()]; !ok {
+		return repository.ErrWebhookNotFound
+	}
+	r.subscriptions[subscription.ID()] = copyWebhookSubscription(subscription)
+	return nil
+}
+
+func (r *webhookSubscriptionRepositoryImpl) Delete(_ context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[id]; !ok {
+		return repository.ErrWebhookNotFound
+	}
+	delete(r.subscriptions, id)
+	return nil
+}
+
+func copyWebhookSubscription(subscription *domain.WebhookSubscription) *domain.WebhookSubscription {
+	return domain.ReconstructWebhookSubscription(
+		subscription.ID(),
+		subscription.URL(),
+		subscription.EventTypes(),
+		subscription.Secret(),
+		subscription.Active(),
+		subscription.CreatedAt(),
+		subscription.UpdatedAt(),
+	)
+}
+
+// webhookDeliveryRepositoryImpl 配信はPostgreSQL上でservices/batchが作成するため、メモリ上では常に空となる
+type webhookDeliveryRepositoryImpl struct{}
+
+func NewWebhookDeliveryRepository() repository.WebhookDeliveryRepository {
+	return &webhookDeliveryRepositoryImpl{}
+}
+
+func (r *webhookDeliveryRepositoryImpl) FindByID(_ context.Context, _, _ uuid.UUID) (*domain.WebhookDelivery, error) {
+	return nil, repository.ErrWebhookDeliveryNotFound
+}
+
+func (r *webhookDeliveryRepositoryImpl) List(_ context.Context, _ repository.WebhookDeliveryListParams) (*repository.WebhookDeliveryListResult, error) {
+	return &repository.WebhookDeliveryListResult{}, nil
+}
+
+func (r *webhookDeliveryRepositoryImpl) ListAttempts(_ context.Context, _ uuid.UUID) ([]domain.WebhookDeliveryAttempt, error) {
+	return nil, nil
+}
+
+func (r *webhookDeliveryRepositoryImpl) Replay(_ context.Context, _ *domain.WebhookDelivery, _ domain.WebhookDeliveryStatus) error {
+	return repository.ErrWebhookDeliveryNotFound
+}
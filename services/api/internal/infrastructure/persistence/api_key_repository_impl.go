@@ -0,0 +1,189 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+const apiKeyColumns = "id, name, owner, prefix, key_hash, scopes, role, expires_at, last_used_at, revoked_at, created_at"
+
+type apiKeyRepositoryImpl struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewAPIKeyRepository(db *sql.DB, logger *zap.Logger) repository.APIKeyRepository {
+	return &apiKeyRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *apiKeyRepositoryImpl) Create(ctx context.Context, key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, name, owner, prefix, key_hash, scopes, role, expires_at, revoked_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err := executor(ctx, r.db).ExecContext(ctx, query,
+		key.ID(),
+		key.Name(),
+		key.Owner(),
+		key.Prefix(),
+		key.KeyHash(),
+		pq.Array(scopeStrings(key.Scopes())),
+		key.Role(),
+		key.ExpiresAt(),
+		key.RevokedAt(),
+		key.CreatedAt(),
+	)
+	if err != nil {
+		err = classifyError(err)
+		if errors.Is(err, repository.ErrUniqueViolation) {
+			return fmt.Errorf("%w: %w", repository.ErrAPIKeyAlreadyExists, err)
+		}
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepositoryImpl) FindByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+
+	key, err := scanAPIKey(executor(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to find api key: %w", classifyError(err))
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepositoryImpl) FindByKeyHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	key, err := scanAPIKey(executor(ctx, r.db).QueryRowContext(ctx, query, keyHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to find api key: %w", classifyError(err))
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepositoryImpl) List(ctx context.Context) ([]*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at DESC, id DESC`
+
+	rows, err := executor(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", classifyError(err))
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			pkglogger.FromContext(ctx).Error("failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", classifyError(err))
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepositoryImpl) UpdateRevokedAt(ctx context.Context, key *domain.APIKey) error {
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2`
+
+	result, err := executor(ctx, r.db).ExecContext(ctx, query, key.RevokedAt(), key.ID())
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", classifyError(err))
+	}
+	return checkAPIKeyUpdated(result)
+}
+
+func (r *apiKeyRepositoryImpl) UpdateLastUsedAt(ctx context.Context, id uuid.UUID, at time.Time) error {
+	// 並行したリクエストで古い時刻に戻さないよう、新しい時刻の場合のみ更新する
+	query := `
+		UPDATE api_keys SET last_used_at = $1
+		WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $1)`
+
+	if _, err := executor(ctx, r.db).ExecContext(ctx, query, at, id); err != nil {
+		return fmt.Errorf("failed to update api key last used at: %w", classifyError(err))
+	}
+	return nil
+}
+
+func checkAPIKeyUpdated(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return repository.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var (
+		id         uuid.UUID
+		name       string
+		owner      string
+		prefix     string
+		keyHash    string
+		scopes     pq.StringArray
+		role       string
+		expiresAt  sql.NullTime
+		lastUsedAt sql.NullTime
+		revokedAt  sql.NullTime
+		createdAt  time.Time
+	)
+	if err := row.Scan(&id, &name, &owner, &prefix, &keyHash, &scopes, &role, &expiresAt, &lastUsedAt, &revokedAt, &createdAt); err != nil {
+		return nil, err
+	}
+
+	apiKeyScopes := make([]domain.APIKeyScope, len(scopes))
+	for i, s := range scopes {
+		apiKeyScopes[i] = domain.APIKeyScope(s)
+	}
+	return domain.ReconstructAPIKey(
+		id,
+		name,
+		owner,
+		prefix,
+		keyHash,
+		apiKeyScopes,
+		domain.Role(role),
+		getTimePtr(expiresAt),
+		getTimePtr(lastUsedAt),
+		getTimePtr(revokedAt),
+		createdAt,
+	), nil
+}
+
+func scopeStrings(scopes []domain.APIKeyScope) []string {
+	s := make([]string, len(scopes))
+	for i, scope := range scopes {
+		s[i] = string(scope)
+	}
+	return s
+}
@@ -0,0 +1,208 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+const auditEventColumns = "id, occurred_at, actor_type, actor_id, action, target_user_id, before, after, request_id, client_ip"
+
+type auditEventRepositoryImpl struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewAuditEventRepository(db *sql.DB, logger *zap.Logger) repository.AuditEventRepository {
+	return &auditEventRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *auditEventRepositoryImpl) Create(ctx context.Context, event *domain.AuditEvent) error {
+	before, err := marshalAuditFields(event.Before())
+	if err != nil {
+		return err
+	}
+	after, err := marshalAuditFields(event.After())
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_events (` + auditEventColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err = executor(ctx, r.db).ExecContext(ctx, query,
+		event.ID(),
+		event.OccurredAt(),
+		event.Actor().Type,
+		event.Actor().ID,
+		event.Action(),
+		event.TargetUserID(),
+		before,
+		after,
+		event.RequestID(),
+		event.ClientIP(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit event: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+func (r *auditEventRepositoryImpl) List(ctx context.Context, params repository.AuditEventListParams) (*repository.AuditEventListResult, error) {
+	where, args := buildAuditEventFilter(params.Filter)
+	if params.After != nil {
+		args = append(args, params.After.OccurredAt, params.After.ID)
+		where += fmt.Sprintf(" AND (occurred_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	// 次ページの有無を判定するため1件多く取得する
+	args = append(args, params.Limit+1)
+	query := `
+		SELECT ` + auditEventColumns + `
+		FROM audit_events
+		WHERE ` + where + `
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := executor(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit events: %w", classifyError(err))
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			pkglogger.FromContext(ctx).Error("failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	var events []*domain.AuditEvent
+	for rows.Next() {
+		event, scanErr := scanAuditEvent(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", scanErr)
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", classifyError(err))
+	}
+
+	result := &repository.AuditEventListResult{}
+	if len(events) > params.Limit {
+		events = events[:params.Limit]
+		last := events[len(events)-1]
+		result.NextCursor = &repository.AuditEventCursor{OccurredAt: last.OccurredAt(), ID: last.ID()}
+	}
+	result.Events = events
+	return result, nil
+}
+
+// buildAuditEventFilter 絞り込み条件からWHERE句とプレースホルダの値を組み立てる
+func buildAuditEventFilter(filter repository.AuditEventFilter) (string, []any) {
+	var (
+		conditions []string
+		args       []any
+	)
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ActorType != "" {
+		add("actor_type = $%d", filter.ActorType)
+	}
+	if filter.ActorID != "" {
+		add("actor_id = $%d", filter.ActorID)
+	}
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+	if filter.TargetUserID != uuid.Nil {
+		add("target_user_id = $%d", filter.TargetUserID)
+	}
+	if filter.From != nil {
+		add("occurred_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("occurred_at < $%d", *filter.To)
+	}
+
+	if len(conditions) == 0 {
+		return "TRUE", args
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+func scanAuditEvent(row rowScanner) (*domain.AuditEvent, error) {
+	var (
+		id           uuid.UUID
+		occurredAt   time.Time
+		actorType    string
+		actorID      string
+		action       string
+		targetUserID uuid.UUID
+		before       []byte
+		after        []byte
+		requestID    string
+		clientIP     string
+	)
+	if err := row.Scan(&id, &occurredAt, &actorType, &actorID, &action, &targetUserID, &before, &after, &requestID, &clientIP); err != nil {
+		return nil, err
+	}
+
+	beforeFields, err := unmarshalAuditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := unmarshalAuditFields(after)
+	if err != nil {
+		return nil, err
+	}
+	return domain.ReconstructAuditEvent(
+		id,
+		occurredAt,
+		domain.AuditActor{Type: domain.AuditActorType(actorType), ID: actorID},
+		domain.AuditAction(action),
+		targetUserID,
+		beforeFields,
+		afterFields,
+		requestID,
+		clientIP,
+	), nil
+}
+
+// marshalAuditFields nilの場合はNULLとして保存する
+func marshalAuditFields(fields map[string]any) ([]byte, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit fields: %w", err)
+	}
+	return b, nil
+}
+
+func unmarshalAuditFields(b []byte) (map[string]any, error) {
+	if b == nil {
+		return nil, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal audit fields: %w", err)
+	}
+	return fields, nil
+}
@@ -0,0 +1,51 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+)
+
+// PostgreSQLのSQLSTATE
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgUniqueViolation      pq.ErrorCode = "23505"
+	pgForeignKeyViolation  pq.ErrorCode = "23503"
+	pgSerializationFailure pq.ErrorCode = "40001"
+	pgQueryCanceled        pq.ErrorCode = "57014" // statement_timeout超過、キャンセル要求
+)
+
+// usersのメールアドレス用部分ユニークインデックス(migration 0005)
+const usersEmailUniqueConstraint = "users_email_unique_not_deleted"
+
+// classifyError *pq.ErrorをSQLSTATEに応じたrepositoryのエラーでwrapする。
+// 元のエラーもwrapしたままのため、errors.Asで*pq.Errorを取り出すこともできる
+func classifyError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case pgUniqueViolation:
+		return fmt.Errorf("%w: %w", repository.ErrUniqueViolation, err)
+	case pgForeignKeyViolation:
+		return fmt.Errorf("%w: %w", repository.ErrForeignKeyViolation, err)
+	case pgSerializationFailure:
+		return fmt.Errorf("%w: %w", repository.ErrSerializationFailure, err)
+	case pgQueryCanceled:
+		return fmt.Errorf("%w: %w", repository.ErrQueryCanceled, err)
+	default:
+		return err
+	}
+}
+
+// isEmailUniqueViolation usersのメールアドレス重複によるエラーか判定
+func isEmailUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) &&
+		pqErr.Code == pgUniqueViolation &&
+		pqErr.Constraint == usersEmailUniqueConstraint
+}
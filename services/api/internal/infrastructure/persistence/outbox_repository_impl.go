@@ -0,0 +1,52 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+type outboxRepositoryImpl struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewOutboxRepository(db *sql.DB, logger *zap.Logger) repository.OutboxRepository {
+	return &outboxRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *outboxRepositoryImpl) Append(ctx context.Context, events []domain.Event) error {
+	query := `
+		INSERT INTO outbox_events (event_id, aggregate_type, aggregate_id, event_type, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	// idの採番順が配信順となるため、1件ずつ順に挿入する
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s event: %w", event.EventType(), err)
+		}
+		_, err = executor(ctx, r.db).ExecContext(ctx, query,
+			uuid.New(),
+			event.AggregateType(),
+			event.AggregateID(),
+			event.EventType(),
+			payload,
+			event.OccurredAt(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to append outbox event: %w", classifyError(err))
+		}
+	}
+
+	return nil
+}
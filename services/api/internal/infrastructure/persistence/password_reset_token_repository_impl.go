@@ -0,0 +1,109 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+type passwordResetTokenRepositoryImpl struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewPasswordResetTokenRepository(db *sql.DB, logger *zap.Logger) repository.PasswordResetTokenRepository {
+	return &passwordResetTokenRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *passwordResetTokenRepositoryImpl) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := executor(ctx, r.db).ExecContext(ctx, query,
+		token.ID(),
+		token.UserID(),
+		token.TokenHash(),
+		token.ExpiresAt(),
+		token.CreatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+func (r *passwordResetTokenRepositoryImpl) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1`
+
+	var (
+		id        uuid.UUID
+		userID    uuid.UUID
+		hash      string
+		expiresAt time.Time
+		usedAt    sql.NullTime
+		createdAt time.Time
+	)
+
+	err := executor(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(
+		&id,
+		&userID,
+		&hash,
+		&expiresAt,
+		&usedAt,
+		&createdAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrResetTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to find password reset token: %w", classifyError(err))
+	}
+
+	return domain.ReconstructPasswordResetToken(
+		id,
+		userID,
+		hash,
+		expiresAt,
+		getTimePtr(usedAt),
+		createdAt,
+	), nil
+}
+
+func (r *passwordResetTokenRepositoryImpl) MarkUsed(ctx context.Context, token *domain.PasswordResetToken) error {
+	// used_at IS NULLを条件に含め、同時に同じトークンが使用された場合も1回のみ成功させる
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL`
+
+	result, err := executor(ctx, r.db).ExecContext(ctx, query, token.UsedAt(), token.ID())
+	if err != nil {
+		return fmt.Errorf("failed to mark password reset token as used: %w", classifyError(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrResetTokenNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+type refreshTokenRepositoryImpl struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewRefreshTokenRepository(db *sql.DB, logger *zap.Logger) repository.RefreshTokenRepository {
+	return &refreshTokenRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *refreshTokenRepositoryImpl) Create(ctx context.Context, token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := executor(ctx, r.db).ExecContext(ctx, query,
+		token.ID(),
+		token.UserID(),
+		token.FamilyID(),
+		token.TokenHash(),
+		token.ExpiresAt(),
+		token.CreatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepositoryImpl) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1`
+
+	var (
+		id        uuid.UUID
+		userID    uuid.UUID
+		familyID  uuid.UUID
+		hash      string
+		expiresAt time.Time
+		usedAt    sql.NullTime
+		revokedAt sql.NullTime
+		createdAt time.Time
+	)
+
+	err := executor(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(
+		&id,
+		&userID,
+		&familyID,
+		&hash,
+		&expiresAt,
+		&usedAt,
+		&revokedAt,
+		&createdAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrRefreshTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to find refresh token: %w", classifyError(err))
+	}
+
+	return domain.ReconstructRefreshToken(
+		id,
+		userID,
+		familyID,
+		hash,
+		expiresAt,
+		getTimePtr(usedAt),
+		getTimePtr(revokedAt),
+		createdAt,
+	), nil
+}
+
+func (r *refreshTokenRepositoryImpl) MarkUsed(ctx context.Context, token *domain.RefreshToken) error {
+	// used_at IS NULLを条件に含め、同時に同じトークンが使用された場合も1回のみ成功させる
+	query := `
+		UPDATE refresh_tokens
+		SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL`
+
+	result, err := executor(ctx, r.db).ExecContext(ctx, query, token.UsedAt(), token.ID())
+	if err != nil {
+		return fmt.Errorf("failed to mark refresh token as used: %w", classifyError(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.ErrRefreshTokenAlreadyUsed
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepositoryImpl) RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE family_id = $2 AND revoked_at IS NULL`
+
+	if _, err := executor(ctx, r.db).ExecContext(ctx, query, at, familyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepositoryImpl) RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE user_id = $2 AND revoked_at IS NULL`
+
+	if _, err := executor(ctx, r.db).ExecContext(ctx, query, at, userID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens of user: %w", classifyError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/tokane888/test-mcp/services/api/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/tokane888/test-mcp/services/api/internal/infrastructure/persistence")
+
+// tracedExecutor SQLの実行ごとにspanを記録する。
+// 引数には個人情報が含まれるため記録せず、placeholderを含むSQL文のみ記録する
+type tracedExecutor struct {
+	next dbExecutor
+}
+
+func (e tracedExecutor) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	result, err := e.next.ExecContext(ctx, query, args...)
+	if err == nil {
+		if n, rowsErr := result.RowsAffected(); rowsErr == nil {
+			span.SetAttributes(attribute.Int64("db.rows_affected", n))
+		}
+	}
+	tracing.EndSpan(span, err)
+	return result, err
+}
+
+// QueryContext spanは結果の読み込み前に終了するため、行の読み込み時間は含まない
+func (e tracedExecutor) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := e.next.QueryContext(ctx, query, args...)
+	tracing.EndSpan(span, err)
+	return rows, err
+}
+
+func (e tracedExecutor) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	row := e.next.QueryRowContext(ctx, query, args...)
+	// sql.ErrNoRowsは正常系のため、Scan前に取得できるエラーのみ記録する
+	tracing.EndSpan(span, row.Err())
+	return row
+}
+
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation := queryOperation(query)
+	return tracer.Start(ctx, "db."+strings.ToLower(operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(strings.Join(strings.Fields(query), " ")),
+		),
+	)
+}
+
+// queryOperation SQL文の先頭のキーワード(SELECT, INSERT等)を返す
+func queryOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
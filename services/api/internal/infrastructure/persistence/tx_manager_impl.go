@@ -0,0 +1,115 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"github.com/tokane888/test-mcp/services/api/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTxMaxRetries = 3
+	// 再試行ごとに倍増させる待機時間の初期値
+	txRetryBaseDelay = 10 * time.Millisecond
+)
+
+type txKey struct{}
+
+// dbExecutor *sql.DBと*sql.Txの共通インターフェース
+type dbExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// executor ctxにトランザクションがあればそれを、無ければdbを返す
+func executor(ctx context.Context, db *sql.DB) dbExecutor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tracedExecutor{next: tx}
+	}
+	return tracedExecutor{next: db}
+}
+
+type txManagerImpl struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewTxManager(db *sql.DB, logger *zap.Logger) repository.TxManager {
+	return &txManagerImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (m *txManagerImpl) WithinTx(ctx context.Context, fn func(ctx context.Context) error, opts ...repository.TxOption) error {
+	// 既にトランザクション内であれば外側のトランザクションに参加する
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	options := repository.TxOptions{
+		Isolation:  sql.LevelDefault,
+		MaxRetries: defaultTxMaxRetries,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	delay := txRetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		err := m.run(ctx, fn, &options)
+		if err == nil || !errors.Is(err, repository.ErrSerializationFailure) || attempt >= options.MaxRetries {
+			return err
+		}
+
+		pkglogger.FromContext(ctx).Warn("retrying transaction after serialization failure",
+			zap.Int("attempt", attempt+1),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (m *txManagerImpl) run(ctx context.Context, fn func(ctx context.Context) error, options *repository.TxOptions) (err error) {
+	ctx, span := tracer.Start(ctx, "db.transaction", trace.WithAttributes(
+		attribute.String("db.transaction.isolation", options.Isolation.String()),
+		attribute.Bool("db.transaction.read_only", options.ReadOnly),
+	))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: options.Isolation,
+		ReadOnly:  options.ReadOnly,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			pkglogger.FromContext(ctx).Error("failed to rollback transaction", zap.Error(rollbackErr))
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+	return nil
+}
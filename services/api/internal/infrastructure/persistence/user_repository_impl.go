@@ -5,14 +5,20 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
 	"github.com/tokane888/test-mcp/services/api/internal/domain"
 	"github.com/tokane888/test-mcp/services/api/internal/repository"
 	"go.uber.org/zap"
 )
 
+// usersテーブルからdomain.Userを復元するためのカラム。scanUserと順序を合わせること
+const userColumns = "id, email, username, password_hash, role, created_at, updated_at, deleted_at, version"
+
 type userRepositoryImpl struct {
 	db     *sql.DB
 	logger *zap.Logger
@@ -27,22 +33,24 @@ func NewUserRepository(db *sql.DB, logger *zap.Logger) repository.UserRepository
 
 func (r *userRepositoryImpl) Create(ctx context.Context, user *domain.User) error {
 	query := `
-		INSERT INTO users (id, email, username, password_hash, created_at, updated_at) 
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		INSERT INTO users (id, email, username, password_hash, role, created_at, updated_at, version) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := executor(ctx, r.db).ExecContext(ctx, query,
 		user.ID(),
 		user.Email(),
 		user.Username(),
 		user.PasswordHash(),
+		user.Role(),
 		user.CreatedAt(),
 		user.UpdatedAt(),
+		user.Version(),
 	)
 	if err != nil {
-		if isUniqueViolation(err) {
+		if isEmailUniqueViolation(err) {
 			return repository.ErrUserAlreadyExists
 		}
-		return fmt.Errorf("failed to create user: %w", err)
+		return fmt.Errorf("failed to create user: %w", classifyError(err))
 	}
 
 	return nil
@@ -50,180 +58,195 @@ func (r *userRepositoryImpl) Create(ctx context.Context, user *domain.User) erro
 
 func (r *userRepositoryImpl) FindByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 	query := `
-		SELECT id, email, username, password_hash, created_at, updated_at, deleted_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1 AND deleted_at IS NULL`
 
-	var (
-		userID       uuid.UUID
-		email        string
-		username     string
-		passwordHash string
-		createdAt    sql.NullTime
-		updatedAt    sql.NullTime
-		deletedAt    sql.NullTime
-	)
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&userID,
-		&email,
-		&username,
-		&passwordHash,
-		&createdAt,
-		&updatedAt,
-		&deletedAt,
-	)
+	user, err := scanUser(executor(ctx, r.db).QueryRowContext(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, repository.ErrUserNotFound
 		}
-		return nil, fmt.Errorf("failed to find user by id: %w", err)
+		return nil, fmt.Errorf("failed to find user by id: %w", classifyError(err))
 	}
 
-	user := domain.ReconstructUser(
-		userID,
-		email,
-		username,
-		passwordHash,
-		createdAt.Time,
-		updatedAt.Time,
-		getTimePtr(deletedAt),
-	)
+	return user, nil
+}
+
+func (r *userRepositoryImpl) FindByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = $1`
+
+	user, err := scanUser(executor(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user by id: %w", classifyError(err))
+	}
 
 	return user, nil
 }
 
 func (r *userRepositoryImpl) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, email, username, password_hash, created_at, updated_at, deleted_at
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE email = $1 AND deleted_at IS NULL`
-
-	var (
-		userID       uuid.UUID
-		userEmail    string
-		username     string
-		passwordHash string
-		createdAt    sql.NullTime
-		updatedAt    sql.NullTime
-		deletedAt    sql.NullTime
-	)
+		WHERE lower(email) = lower($1) AND deleted_at IS NULL`
 
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&userID,
-		&userEmail,
-		&username,
-		&passwordHash,
-		&createdAt,
-		&updatedAt,
-		&deletedAt,
-	)
+	user, err := scanUser(executor(ctx, r.db).QueryRowContext(ctx, query, email))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, repository.ErrUserNotFound
 		}
-		return nil, fmt.Errorf("failed to find user by email: %w", err)
+		return nil, fmt.Errorf("failed to find user by email: %w", classifyError(err))
 	}
 
-	user := domain.ReconstructUser(
-		userID,
-		userEmail,
-		username,
-		passwordHash,
-		createdAt.Time,
-		updatedAt.Time,
-		getTimePtr(deletedAt),
-	)
-
 	return user, nil
 }
 
-func (r *userRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*domain.User, int, error) {
+func (r *userRepositoryImpl) List(ctx context.Context, params repository.UserListParams) (*repository.UserListResult, error) {
+	// ソート項目はホワイトリストのカラムのみ許可する(SQLインジェクション対策)
+	sortColumn, ok := userSortColumns[params.Sort.Field]
+	if !ok {
+		return nil, repository.ErrInvalidSortField
+	}
+	direction, comparator := "ASC", ">"
+	if params.Sort.Desc {
+		direction, comparator = "DESC", "<"
+	}
+
+	where, args := buildUserFilter(params.Filter)
+	result := &repository.UserListResult{}
+
 	// Count total users
-	var total int
-	countQuery := "SELECT COUNT(*) FROM users WHERE deleted_at IS NULL"
-	err := r.db.QueryRowContext(ctx, countQuery).Scan(&total)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	if !params.SkipTotal {
+		var total int
+		countQuery := "SELECT COUNT(*) FROM users WHERE " + where
+		err := executor(ctx, r.db).QueryRowContext(ctx, countQuery, args...).Scan(&total)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count users: %w", classifyError(err))
+		}
+		result.Total = &total
 	}
 
 	// Get users
+	if params.After != nil {
+		args = append(args, params.After.Value, params.After.ID)
+		where += fmt.Sprintf(" AND (%s, id) %s ($%d, $%d)", sortColumn, comparator, len(args)-1, len(args))
+	}
+	// 次ページの有無を判定するため1件多く取得する
+	args = append(args, params.Limit+1)
 	query := `
-		SELECT id, email, username, password_hash, created_at, updated_at, deleted_at
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE deleted_at IS NULL
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2`
+		WHERE ` + where + `
+		ORDER BY ` + sortColumn + ` ` + direction + `, id ` + direction + `
+		LIMIT $` + strconv.Itoa(len(args))
+	if params.After == nil {
+		args = append(args, params.Offset)
+		query += " OFFSET $" + strconv.Itoa(len(args))
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := executor(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to query users: %w", err)
+		return nil, fmt.Errorf("failed to query users: %w", classifyError(err))
 	}
 	defer func() {
 		if closeErr := rows.Close(); closeErr != nil {
-			r.logger.Error("failed to close rows", zap.Error(closeErr))
+			pkglogger.FromContext(ctx).Error("failed to close rows", zap.Error(closeErr))
 		}
 	}()
 
 	var users []*domain.User
 	for rows.Next() {
-		var (
-			userID       uuid.UUID
-			email        string
-			username     string
-			passwordHash string
-			createdAt    sql.NullTime
-			updatedAt    sql.NullTime
-			deletedAt    sql.NullTime
-		)
-
-		if scanErr := rows.Scan(
-			&userID,
-			&email,
-			&username,
-			&passwordHash,
-			&createdAt,
-			&updatedAt,
-			&deletedAt,
-		); scanErr != nil {
-			return nil, 0, fmt.Errorf("failed to scan user: %w", scanErr)
+		user, scanErr := scanUser(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", scanErr)
 		}
-
-		user := domain.ReconstructUser(
-			userID,
-			email,
-			username,
-			passwordHash,
-			createdAt.Time,
-			updatedAt.Time,
-			getTimePtr(deletedAt),
-		)
-
 		users = append(users, user)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("row iteration error: %w", err)
+		return nil, fmt.Errorf("row iteration error: %w", classifyError(err))
 	}
 
-	return users, total, nil
+	if len(users) > params.Limit {
+		users = users[:params.Limit]
+		last := users[len(users)-1]
+		result.NextCursor = &repository.UserCursor{
+			Value: userSortValue(last, params.Sort.Field),
+			ID:    last.ID(),
+		}
+	}
+	result.Users = users
+
+	return result, nil
 }
 
 func (r *userRepositoryImpl) Update(ctx context.Context, user *domain.User) error {
+	// versionが読み込み時から変わっていない場合のみ更新する(楽観的排他制御)
 	query := `
 		UPDATE users
-		SET email = $1, username = $2, updated_at = $3, deleted_at = $4
-		WHERE id = $5`
+		SET email = $1, username = $2, role = $3, updated_at = $4, deleted_at = $5, version = version + 1
+		WHERE id = $6 AND version = $7`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := executor(ctx, r.db).ExecContext(ctx, query,
 		user.Email(),
 		user.Username(),
+		user.Role(),
 		user.UpdatedAt(),
 		user.DeletedAt(),
 		user.ID(),
+		user.Version(),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
+		if isEmailUniqueViolation(err) {
+			return repository.ErrUserAlreadyExists
+		}
+		return fmt.Errorf("failed to update user: %w", classifyError(err))
+	}
+
+	return r.checkVersionedUpdate(ctx, result, user)
+}
+
+func (r *userRepositoryImpl) UpdatePassword(ctx context.Context, user *domain.User) error {
+	query := `
+		UPDATE users
+		SET password_hash = $1, updated_at = $2, version = version + 1
+		WHERE id = $3 AND version = $4 AND deleted_at IS NULL`
+
+	result, err := executor(ctx, r.db).ExecContext(ctx, query,
+		user.PasswordHash(),
+		user.UpdatedAt(),
+		user.ID(),
+		user.Version(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", classifyError(err))
+	}
+
+	return r.checkVersionedUpdate(ctx, result, user)
+}
+
+func (r *userRepositoryImpl) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	query := "SELECT EXISTS(SELECT 1 FROM users WHERE lower(email) = lower($1) AND deleted_at IS NULL)"
+	var exists bool
+	err := executor(ctx, r.db).QueryRowContext(ctx, query, email).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if user exists: %w", classifyError(err))
+	}
+	return exists, nil
+}
+
+func (r *userRepositoryImpl) HardDelete(ctx context.Context, id uuid.UUID) error {
+	query := "DELETE FROM users WHERE id = $1"
+
+	result, err := executor(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", classifyError(err))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -238,25 +261,136 @@ func (r *userRepositoryImpl) Update(ctx context.Context, user *domain.User) erro
 	return nil
 }
 
-func (r *userRepositoryImpl) ExistsByEmail(ctx context.Context, email string) (bool, error) {
-	query := "SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND deleted_at IS NULL)"
-	var exists bool
-	err := r.db.QueryRowContext(ctx, query, email).Scan(&exists)
+// checkVersionedUpdate versionを条件にしたUPDATEの結果を判定する。
+// 更新できなかった場合、行が存在すれば他者による更新と判断しErrConflictを返す
+func (r *userRepositoryImpl) checkVersionedUpdate(ctx context.Context, result sql.Result, user *domain.User) error {
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return false, fmt.Errorf("failed to check if user exists: %w", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
-	return exists, nil
+
+	if rowsAffected == 0 {
+		var exists bool
+		query := "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)"
+		if err := executor(ctx, r.db).QueryRowContext(ctx, query, user.ID()).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check if user exists: %w", classifyError(err))
+		}
+		if exists {
+			return repository.ErrConflict
+		}
+		return repository.ErrUserNotFound
+	}
+
+	user.IncrementVersion()
+	return nil
 }
 
-// Helper function to detect PostgreSQL unique constraint violation
-func isUniqueViolation(err error) bool {
-	return err != nil && (err.Error() == "pq: duplicate key value violates unique constraint \"users_email_key\"" ||
-		err.Error() == "pq: duplicate key value violates unique constraint \"users_email_unique_not_deleted\"" ||
-		containsString(err.Error(), "duplicate key value violates unique constraint"))
+// ソート項目とカラム名の対応。ORDER BYに埋め込むためここにあるカラムのみ許可する
+var userSortColumns = map[repository.UserSortField]string{
+	repository.UserSortByCreatedAt: "created_at",
+	repository.UserSortByUpdatedAt: "updated_at",
+	repository.UserSortByUsername:  "username",
+	repository.UserSortByEmail:     "email",
+}
+
+func userSortValue(user *domain.User, field repository.UserSortField) any {
+	switch field {
+	case repository.UserSortByUpdatedAt:
+		return user.UpdatedAt()
+	case repository.UserSortByUsername:
+		return user.Username()
+	case repository.UserSortByEmail:
+		return user.Email()
+	default:
+		return user.CreatedAt()
+	}
 }
 
-func containsString(s, substr string) bool {
-	return len(s) >= len(substr) && s[:len(substr)] == substr || len(s) > len(substr) && containsString(s[1:], substr)
+// buildUserFilter 絞り込み条件からWHERE句とプレースホルダの値を組み立てる
+func buildUserFilter(filter repository.UserFilter) (string, []any) {
+	var (
+		conditions []string
+		args       []any
+	)
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	if filter.EmailDomain != "" {
+		add("lower(split_part(email, '@', 2)) = lower($%d)", filter.EmailDomain)
+	}
+	if filter.UsernamePrefix != "" {
+		add(`username ILIKE $%d ESCAPE '\'`, escapeLike(filter.UsernamePrefix)+"%")
+	}
+	if filter.UsernameContains != "" {
+		add(`username ILIKE $%d ESCAPE '\'`, "%"+escapeLike(filter.UsernameContains)+"%")
+	}
+	if filter.CreatedFrom != nil {
+		add("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		add("created_at < $%d", *filter.CreatedTo)
+	}
+
+	if len(conditions) == 0 {
+		return "TRUE", args
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// escapeLike LIKEのワイルドカードをエスケープする
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row rowScanner) (*domain.User, error) {
+	var (
+		userID       uuid.UUID
+		email        string
+		username     string
+		passwordHash string
+		role         string
+		createdAt    sql.NullTime
+		updatedAt    sql.NullTime
+		deletedAt    sql.NullTime
+		version      int
+	)
+
+	if err := row.Scan(
+		&userID,
+		&email,
+		&username,
+		&passwordHash,
+		&role,
+		&createdAt,
+		&updatedAt,
+		&deletedAt,
+		&version,
+	); err != nil {
+		return nil, err
+	}
+
+	return domain.ReconstructUser(
+		userID,
+		email,
+		username,
+		passwordHash,
+		domain.Role(role),
+		createdAt.Time,
+		updatedAt.Time,
+		getTimePtr(deletedAt),
+		version,
+	), nil
 }
 
 func getTimePtr(nt sql.NullTime) *time.Time {
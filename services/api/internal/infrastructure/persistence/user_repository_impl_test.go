@@ -0,0 +1,55 @@
+package persistence_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/tokane888/test-mcp/services/api/internal/infrastructure/persistence"
+	"github.com/tokane888/test-mcp/services/api/internal/migration"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"github.com/tokane888/test-mcp/services/api/internal/repository/repositorytest"
+	"go.uber.org/zap"
+)
+
+// テスト用DBの接続文字列を指定する環境変数。テーブルの内容は削除されるため、専用のDBを指定すること
+const testDSNEnv = "TEST_DATABASE_DSN"
+
+func TestUserRepository(t *testing.T) {
+	db := openTestDB(t)
+	repositorytest.RunUserRepositoryTests(t, func(t *testing.T) repository.UserRepository {
+		// 関連テーブルはON DELETE CASCADEのため、usersと合わせて空にする
+		if _, err := db.ExecContext(context.Background(), "TRUNCATE users CASCADE"); err != nil {
+			t.Fatalf("failed to truncate users: %v", err)
+		}
+		return persistence.NewUserRepository(db, zap.NewNop())
+	})
+}
+
+// openTestDB TEST_DATABASE_DSNのDBへ接続し、migrationを適用する。未設定の場合はテストをskipする
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	migrator, err := migration.NewMigrator(db, zap.NewNop())
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	return db
+}
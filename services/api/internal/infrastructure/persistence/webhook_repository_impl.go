@@ -0,0 +1,334 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+const webhookSubscriptionColumns = "id, url, event_types, secret, active, created_at, updated_at"
+
+type webhookSubscriptionRepositoryImpl struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewWebhookSubscriptionRepository(db *sql.DB, logger *zap.Logger) repository.WebhookSubscriptionRepository {
+	return &webhookSubscriptionRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *webhookSubscriptionRepositoryImpl) Create(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (` + webhookSubscriptionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := executor(ctx, r.db).ExecContext(ctx, query,
+		subscription.ID(),
+		subscription.URL(),
+		pq.Array(subscription.EventTypes()),
+		subscription.Secret(),
+		subscription.Active(),
+		subscription.CreatedAt(),
+		subscription.UpdatedAt(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", classifyError(err))
+	}
+	return nil
+}
+
+func (r *webhookSubscriptionRepositoryImpl) FindByID(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	subscription, err := scanWebhookSubscription(executor(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to find webhook subscription: %w", classifyError(err))
+	}
+	return subscription, nil
+}
+
+func (r *webhookSubscriptionRepositoryImpl) List(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions ORDER BY created_at DESC, id DESC`
+
+	rows, err := executor(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", classifyError(err))
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			pkglogger.FromContext(ctx).Error("failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	var subscriptions []*domain.WebhookSubscription
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", classifyError(err))
+	}
+	return subscriptions, nil
+}
+
+func (r *webhookSubscriptionRepositoryImpl) Update(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $1, event_types = $2, active = $3, updated_at = $4
+		WHERE id = $5`
+
+	result, err := executor(ctx, r.db).ExecContext(ctx, query,
+		subscription.URL(),
+		pq.Array(subscription.EventTypes()),
+		subscription.Active(),
+		subscription.UpdatedAt(),
+		subscription.ID(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription: %w", classifyError(err))
+	}
+	return checkWebhookAffected(result, repository.ErrWebhookNotFound)
+}
+
+func (r *webhookSubscriptionRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
+	// 配信と送信の記録は外部キーのON DELETE CASCADEにより削除される
+	result, err := executor(ctx, r.db).ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", classifyError(err))
+	}
+	return checkWebhookAffected(result, repository.ErrWebhookNotFound)
+}
+
+func scanWebhookSubscription(row rowScanner) (*domain.WebhookSubscription, error) {
+	var (
+		id         uuid.UUID
+		url        string
+		eventTypes pq.StringArray
+		secret     string
+		active     bool
+		createdAt  time.Time
+		updatedAt  time.Time
+	)
+	if err := row.Scan(&id, &url, &eventTypes, &secret, &active, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	return domain.ReconstructWebhookSubscription(id, url, eventTypes, secret, active, createdAt, updatedAt), nil
+}
+
+const webhookDeliveryColumns = "id, subscription_id, event_id, event_type, payload, status, attempts, " +
+	"next_attempt_at, last_attempt_at, last_status_code, last_error, created_at, updated_at"
+
+type webhookDeliveryRepositoryImpl struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func NewWebhookDeliveryRepository(db *sql.DB, logger *zap.Logger) repository.WebhookDeliveryRepository {
+	return &webhookDeliveryRepositoryImpl{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *webhookDeliveryRepositoryImpl) FindByID(ctx context.Context, subscriptionID, id uuid.UUID) (*domain.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1 AND subscription_id = $2`
+
+	delivery, err := scanWebhookDelivery(executor(ctx, r.db).QueryRowContext(ctx, query, id, subscriptionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to find webhook delivery: %w", classifyError(err))
+	}
+	return delivery, nil
+}
+
+func (r *webhookDeliveryRepositoryImpl) List(ctx context.Context, params repository.WebhookDeliveryListParams) (*repository.WebhookDeliveryListResult, error) {
+	where := "subscription_id = $1"
+	args := []any{params.SubscriptionID}
+	if params.Status != "" {
+		args = append(args, params.Status)
+		where += " AND status = $" + strconv.Itoa(len(args))
+	}
+	if params.After != nil {
+		args = append(args, params.After.CreatedAt, params.After.ID)
+		where += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	// 次ページの有無を判定するため1件多く取得する
+	args = append(args, params.Limit+1)
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE ` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := executor(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", classifyError(err))
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			pkglogger.FromContext(ctx).Error("failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		delivery, scanErr := scanWebhookDelivery(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", scanErr)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", classifyError(err))
+	}
+
+	result := &repository.WebhookDeliveryListResult{}
+	if len(deliveries) > params.Limit {
+		deliveries = deliveries[:params.Limit]
+		last := deliveries[len(deliveries)-1]
+		result.NextCursor = &repository.WebhookDeliveryCursor{CreatedAt: last.CreatedAt(), ID: last.ID()}
+	}
+	result.Deliveries = deliveries
+	return result, nil
+}
+
+func (r *webhookDeliveryRepositoryImpl) ListAttempts(ctx context.Context, deliveryID uuid.UUID) ([]domain.WebhookDeliveryAttempt, error) {
+	query := `
+		SELECT attempted_at, status_code, error, duration_ms
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY attempted_at DESC, id DESC`
+
+	rows, err := executor(ctx, r.db).QueryContext(ctx, query, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook delivery attempts: %w", classifyError(err))
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			pkglogger.FromContext(ctx).Error("failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	var attempts []domain.WebhookDeliveryAttempt
+	for rows.Next() {
+		var (
+			attemptedAt time.Time
+			statusCode  sql.NullInt64
+			errMsg      sql.NullString
+			durationMs  int64
+		)
+		if err := rows.Scan(&attemptedAt, &statusCode, &errMsg, &durationMs); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery attempt: %w", err)
+		}
+		attempts = append(attempts, domain.WebhookDeliveryAttempt{
+			AttemptedAt: attemptedAt,
+			StatusCode:  getIntPtr(statusCode),
+			Error:       errMsg.String,
+			Duration:    time.Duration(durationMs) * time.Millisecond,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", classifyError(err))
+	}
+	return attempts, nil
+}
+
+func (r *webhookDeliveryRepositoryImpl) Replay(ctx context.Context, delivery *domain.WebhookDelivery, previousStatus domain.WebhookDeliveryStatus) error {
+	// webhook-dispatcherが並行して状態を更新した場合に上書きしないよう、読み込み時の状態を条件とする
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, updated_at = $4
+		WHERE id = $5 AND status = $6`
+
+	result, err := executor(ctx, r.db).ExecContext(ctx, query,
+		delivery.Status(),
+		delivery.Attempts(),
+		delivery.NextAttemptAt(),
+		delivery.UpdatedAt(),
+		delivery.ID(),
+		previousStatus,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to replay webhook delivery: %w", classifyError(err))
+	}
+	return checkWebhookAffected(result, repository.ErrConflict)
+}
+
+func scanWebhookDelivery(row rowScanner) (*domain.WebhookDelivery, error) {
+	var (
+		id             uuid.UUID
+		subscriptionID uuid.UUID
+		eventID        uuid.UUID
+		eventType      string
+		payload        []byte
+		status         string
+		attempts       int
+		nextAttemptAt  sql.NullTime
+		lastAttemptAt  sql.NullTime
+		lastStatusCode sql.NullInt64
+		lastError      sql.NullString
+		createdAt      time.Time
+		updatedAt      time.Time
+	)
+	if err := row.Scan(&id, &subscriptionID, &eventID, &eventType, &payload, &status, &attempts,
+		&nextAttemptAt, &lastAttemptAt, &lastStatusCode, &lastError, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	return domain.ReconstructWebhookDelivery(
+		id,
+		subscriptionID,
+		eventID,
+		eventType,
+		payload,
+		domain.WebhookDeliveryStatus(status),
+		attempts,
+		getTimePtr(nextAttemptAt),
+		getTimePtr(lastAttemptAt),
+		getIntPtr(lastStatusCode),
+		lastError.String,
+		createdAt,
+		updatedAt,
+	), nil
+}
+
+// checkWebhookAffected 更新対象の行が存在しない場合はnotFoundを返す
+func checkWebhookAffected(result sql.Result, notFound error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+func getIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := int(v.Int64)
+	return &i
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "api"
+
+// ルーティングされなかったリクエストのroute label。
+// 生のpathをlabelにするとseriesが際限なく増えるため一つにまとめる
+const unmatchedRoute = "unmatched"
+
+// Metrics Prometheus向けの指標を保持する
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	usersCreated  prometheus.Counter
+	usersDeleted  *prometheus.CounterVec
+	usersRestored prometheus.Counter
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route template, method and status.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route template, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		usersCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "users_created_total",
+			Help:      "Number of users created.",
+		}),
+		usersDeleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "users_deleted_total",
+			Help:      "Number of users deleted by type (soft, hard).",
+		}, []string{"type"}),
+		usersRestored: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "users_restored_total",
+			Help:      "Number of soft-deleted users restored.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.usersCreated,
+		m.usersDeleted,
+		m.usersRestored,
+	)
+	// 値が0の場合もseriesを出力するため初期化しておく
+	m.usersDeleted.WithLabelValues("soft")
+	m.usersDeleted.WithLabelValues("hard")
+	return m
+}
+
+// RegisterDB コネクションプールの統計(go_sql_*)をdbNameのlabel付きで出力対象に追加する
+func (m *Metrics) RegisterDB(db *sql.DB, dbName string) error {
+	if err := m.registry.Register(collectors.NewDBStatsCollector(db, dbName)); err != nil {
+		return fmt.Errorf("failed to register db stats collector: %w", err)
+	}
+	return nil
+}
+
+// ObserveHTTPRequest routeはc.FullPath()のようなroute template。空文字の場合はルーティングされなかったものとして扱う
+func (m *Metrics) ObserveHTTPRequest(route, method string, status int, duration time.Duration) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	s := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(route, method, s).Inc()
+	m.httpDuration.WithLabelValues(route, method, s).Observe(duration.Seconds())
+}
+
+func (m *Metrics) UserCreated() {
+	m.usersCreated.Inc()
+}
+
+func (m *Metrics) UserDeleted() {
+	m.usersDeleted.WithLabelValues("soft").Inc()
+}
+
+func (m *Metrics) UserHardDeleted() {
+	m.usersDeleted.WithLabelValues("hard").Inc()
+}
+
+func (m *Metrics) UserRestored() {
+	m.usersRestored.Inc()
+}
+
+// Handler /metrics向けのhandler
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
@@ -1,5 +1,3 @@
--- Database initialization for API sample
--- This script creates the initial database schema
 -- Enable UUID extension
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
@@ -20,16 +18,15 @@ CREATE TABLE IF NOT EXISTS users (
 );
 
 -- Create updated_at trigger function
-CREATE
-OR REPLACE FUNCTION update_updated_at_column() RETURNS TRIGGER AS $ $ BEGIN NEW.updated_at = CURRENT_TIMESTAMP;
-
-RETURN NEW;
-
+CREATE OR REPLACE FUNCTION update_updated_at_column() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
 END;
-
-$ $ language 'plpgsql';
+$$ LANGUAGE plpgsql;
 
 -- Create trigger for updated_at
-CREATE TRIGGER update_users_updated_at BEFORE
+-- 旧db/init/01_create_tables.sqlで作成済みのDBにも適用できるようCREATE OR REPLACEを使用
+CREATE OR REPLACE TRIGGER update_users_updated_at BEFORE
 UPDATE
     ON users FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+-- Add version column to users for optimistic concurrency control
+-- 更新のたびにアプリケーション側でインクリメントし、UPDATEのWHERE句で読み込み時の値と比較する
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- Index for keyset pagination of users
+-- ListUsersのORDER BY created_at DESC, id DESCおよび(created_at, id) < (...)の条件に対応
+CREATE INDEX IF NOT EXISTS idx_users_created_at_id ON users (created_at DESC, id DESC) WHERE deleted_at IS NULL;
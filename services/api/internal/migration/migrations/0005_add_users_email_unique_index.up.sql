@@ -0,0 +1,5 @@
+-- Enforce email uniqueness among active users
+-- 論理削除済みユーザーと同じメールアドレスでの再登録を許可するため部分インデックスとする
+-- 大文字小文字の違いのみのメールアドレスも重複とみなす
+-- 既存データに重複がある場合は失敗するため、事前に解消しておくこと
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_unique_not_deleted ON users (lower(email)) WHERE deleted_at IS NULL;
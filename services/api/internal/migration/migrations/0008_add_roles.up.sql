@@ -0,0 +1,16 @@
+-- Add role column to users and api_keys for role-based access control
+-- 既存のユーザーはエンドユーザー(self)とする
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'self'
+    CONSTRAINT users_role_check CHECK (role IN ('admin', 'operator', 'viewer', 'self'));
+
+-- 既存のAPI Keyはscopeで許可された操作に対応するroleとする
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS role VARCHAR(20);
+UPDATE api_keys SET role = CASE
+    WHEN 'admin' = ANY(scopes) THEN 'admin'
+    WHEN 'users:write' = ANY(scopes) THEN 'operator'
+    ELSE 'viewer'
+END
+WHERE role IS NULL;
+ALTER TABLE api_keys ALTER COLUMN role SET NOT NULL;
+ALTER TABLE api_keys DROP CONSTRAINT IF EXISTS api_keys_role_check;
+ALTER TABLE api_keys ADD CONSTRAINT api_keys_role_check CHECK (role IN ('admin', 'operator', 'viewer'));
@@ -0,0 +1,20 @@
+-- Create audit_events table
+-- ユーザーの物理削除後も記録を残すため、target_user_idは外部キーとしない
+CREATE TABLE IF NOT EXISTS audit_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    actor_type VARCHAR(20) NOT NULL,
+    -- API KeyのID、またはユーザーのID。systemの場合は空文字
+    actor_id VARCHAR(64) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    target_user_id UUID NOT NULL,
+    -- 変更されたフィールドの変更前後の値。パスワードハッシュ等の秘匿情報は含まない
+    before JSONB,
+    after JSONB,
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    client_ip VARCHAR(45) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit_events(occurred_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target_user_id ON audit_events(target_user_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor_type, actor_id, occurred_at DESC);
@@ -0,0 +1,21 @@
+-- Create outbox_events table
+-- ドメインイベントを対象の書き込みと同じトランザクションで保存し、services/batchのoutbox-relayが外部へ配信する
+CREATE TABLE IF NOT EXISTS outbox_events (
+    -- 挿入順の連番。relayは同じ集約のイベントをこの順に配信する
+    -- 同じ集約への書き込みは行ロックにより直列化されるため、集約内ではcommit順とも一致する
+    id BIGSERIAL PRIMARY KEY,
+    -- 配信先での重複排除用のID。at-least-onceのため同じイベントが複数回配信される場合がある
+    event_id UUID NOT NULL UNIQUE,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP WITH TIME ZONE,
+    -- 配信に失敗した回数と直近のエラー
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unpublished ON outbox_events(id) WHERE published_at IS NULL;
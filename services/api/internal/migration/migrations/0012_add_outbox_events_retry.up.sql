@@ -0,0 +1,10 @@
+-- Add retry scheduling and dead state to outbox_events
+-- 次回の配信予定時刻。失敗時はバックオフ後、relayが取得した時はlease後の時刻とする
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+-- 配信の失敗が上限回数に達し、配信を諦めた時刻
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS dead_at TIMESTAMP WITH TIME ZONE;
+
+-- relayは未配信のイベントを集約ごとに先頭から確認する
+DROP INDEX IF EXISTS idx_outbox_events_unpublished;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events(aggregate_type, aggregate_id, id)
+    WHERE published_at IS NULL AND dead_at IS NULL;
@@ -0,0 +1,292 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+)
+
+// Auth エンドポイントの認証方式
+type Auth int
+
+const (
+	AuthNone Auth = iota
+	// X-API-Keyヘッダー
+	AuthAPIKey
+	// Authorization: Bearer <アクセストークン>
+	AuthBearer
+)
+
+const (
+	securityAPIKey = "ApiKeyAuth"
+	securityBearer = "BearerAuth"
+)
+
+// Endpoint 1エンドポイント分の定義。routerへの登録と対応させる
+type Endpoint struct {
+	Method string
+	// gin形式のパス(/api/v1/users/:id)
+	Path        string
+	Tag         string
+	Summary     string
+	Description string
+	Auth        Auth
+	// 必要なAPI Keyのscope。AuthAPIKeyの場合のみ使用する
+	Scope string
+	// rate limitの対象
+	RateLimited bool
+	// If-Matchヘッダーによる楽観的排他制御に対応する
+	IfMatch bool
+	// ShouldBindQueryで読み込むstruct
+	Query any
+	// リクエストボディ(JSON)として読み込むstruct
+	Body any
+	// Bodyを省略可能
+	BodyOptional bool
+	Success      Success
+	// handlerが返すエラー。認証、rate limit、リクエストの形式、500のエラーは自動で追加する
+	Errors []ErrorCase
+}
+
+// Success 成功時のレスポンス
+type Success struct {
+	Status int
+	// nilの場合はbodyなし
+	Body any
+	// ETagヘッダーを返す
+	ETag bool
+}
+
+// ErrorCase response.Errorのcodeとステータスの組
+type ErrorCase struct {
+	Status int
+	Code   string
+}
+
+// Generate エンドポイントの定義からドキュメントを生成する
+func Generate(info Info, tags []Tag, endpoints []Endpoint) (*Document, error) {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+		Tags:    tags,
+		Components: Components{
+			SecuritySchemes: map[string]*SecurityScheme{
+				securityAPIKey: {Type: "apiKey", Name: "X-API-Key", In: "header", Description: "管理者が発行したAPI Key"},
+				securityBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "/api/v1/auth/loginで取得したアクセストークン"},
+			},
+		},
+	}
+	registry := newSchemaRegistry()
+	errorRef, err := registry.typeSchema(reflect.TypeFor[response.Error](), false)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, endpoint := range endpoints {
+		op, err := buildOperation(registry, errorRef, &endpoint)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", endpoint.Method, endpoint.Path, err))
+			continue
+		}
+		path := toOpenAPIPath(endpoint.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		method := strings.ToLower(endpoint.Method)
+		if _, dup := (*item)[method]; dup {
+			errs = append(errs, fmt.Errorf("%s %s: duplicated endpoint", endpoint.Method, endpoint.Path))
+			continue
+		}
+		(*item)[method] = op
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	doc.Components.Schemas = registry.schemas
+	return doc, nil
+}
+
+func buildOperation(registry *schemaRegistry, errorRef *Schema, endpoint *Endpoint) (*Operation, error) {
+	op := &Operation{
+		OperationID: operationID(endpoint),
+		Summary:     endpoint.Summary,
+		Description: endpoint.Description,
+		Responses:   make(map[string]*Response),
+		Security:    []SecurityRequirement{},
+	}
+	if endpoint.Tag != "" {
+		op.Tags = []string{endpoint.Tag}
+	}
+	errorCases := slices.Clone(endpoint.Errors)
+
+	for _, name := range pathParams(endpoint.Path) {
+		schema := &Schema{Type: "string"}
+		if name == "id" || strings.HasSuffix(name, "_id") {
+			schema.Format = "uuid"
+		}
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: schema})
+	}
+	if len(op.Parameters) > 0 {
+		errorCases = append(errorCases, ErrorCase{http.StatusBadRequest, "INVALID_ID"})
+	}
+
+	if endpoint.Query != nil {
+		params, err := registry.queryParameters(reflect.TypeOf(endpoint.Query))
+		if err != nil {
+			return nil, err
+		}
+		op.Parameters = append(op.Parameters, params...)
+		errorCases = append(errorCases, ErrorCase{http.StatusBadRequest, "INVALID_REQUEST"})
+	}
+	if endpoint.IfMatch {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        "If-Match",
+			In:          "header",
+			Description: "取得時のETag(カンマ区切りで複数指定可)。指定した場合、いずれも現在のリソースと一致しなければ412を返す。弱いETagは一致しない",
+			Schema:      &Schema{Type: "string"},
+		})
+		errorCases = append(errorCases, ErrorCase{http.StatusPreconditionFailed, "PRECONDITION_FAILED"})
+	}
+
+	if endpoint.Body != nil {
+		schema, err := registry.typeSchema(reflect.TypeOf(endpoint.Body), true)
+		if err != nil {
+			return nil, err
+		}
+		op.RequestBody = &RequestBody{
+			Required: !endpoint.BodyOptional,
+			Content:  map[string]MediaType{"application/json": {Schema: schema}},
+		}
+		errorCases = append(errorCases, ErrorCase{http.StatusBadRequest, "INVALID_REQUEST"})
+	}
+
+	switch endpoint.Auth {
+	case AuthAPIKey:
+		op.Security = []SecurityRequirement{{securityAPIKey: {}}}
+		errorCases = append(errorCases,
+			ErrorCase{http.StatusUnauthorized, "MISSING_API_KEY"},
+			ErrorCase{http.StatusUnauthorized, "INVALID_API_KEY"},
+		)
+		if endpoint.Scope != "" {
+			op.Description = strings.TrimSpace(op.Description + "\n\n必要なscope: `" + endpoint.Scope + "`")
+			errorCases = append(errorCases, ErrorCase{http.StatusForbidden, "INSUFFICIENT_SCOPE"})
+		}
+	case AuthBearer:
+		op.Security = []SecurityRequirement{{securityBearer: {}}}
+		errorCases = append(errorCases,
+			ErrorCase{http.StatusUnauthorized, "MISSING_ACCESS_TOKEN"},
+			ErrorCase{http.StatusUnauthorized, "INVALID_ACCESS_TOKEN"},
+		)
+	}
+	if endpoint.RateLimited {
+		errorCases = append(errorCases, ErrorCase{http.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED"})
+	}
+	errorCases = append(errorCases, ErrorCase{http.StatusInternalServerError, "INTERNAL_ERROR"})
+
+	success, err := buildSuccess(registry, &endpoint.Success)
+	if err != nil {
+		return nil, err
+	}
+	op.Responses[strconv.Itoa(endpoint.Success.Status)] = success
+	for status, codes := range groupErrorCases(errorCases) {
+		op.Responses[strconv.Itoa(status)] = errorResponse(errorRef, status, codes)
+	}
+	return op, nil
+}
+
+func buildSuccess(registry *schemaRegistry, success *Success) (*Response, error) {
+	if success.Status == 0 {
+		return nil, errors.New("success status is not set")
+	}
+	resp := &Response{Description: http.StatusText(success.Status)}
+	if success.Body != nil {
+		schema, err := registry.typeSchema(reflect.TypeOf(success.Body), false)
+		if err != nil {
+			return nil, err
+		}
+		resp.Content = map[string]MediaType{"application/json": {Schema: schema}}
+	}
+	if success.ETag {
+		resp.Headers = map[string]Header{
+			"ETag": {Description: "リソースのversion。更新、削除時のIf-Matchに指定する", Schema: &Schema{Type: "string"}},
+		}
+	}
+	return resp, nil
+}
+
+// groupErrorCases ステータスごとに重複を除いたcodeをまとめる
+func groupErrorCases(cases []ErrorCase) map[int][]string {
+	grouped := make(map[int][]string)
+	for _, c := range cases {
+		if !slices.Contains(grouped[c.Status], c.Code) {
+			grouped[c.Status] = append(grouped[c.Status], c.Code)
+		}
+	}
+	for _, codes := range grouped {
+		slices.Sort(codes)
+	}
+	return grouped
+}
+
+// errorResponse codeをenumに限定したresponse.Errorのレスポンス
+func errorResponse(errorRef *Schema, status int, codes []string) *Response {
+	enum := make([]any, len(codes))
+	for i, code := range codes {
+		enum[i] = code
+	}
+	return &Response{
+		Description: http.StatusText(status) + ": " + strings.Join(codes, ", "),
+		Content: map[string]MediaType{"application/json": {Schema: &Schema{
+			AllOf: []*Schema{
+				errorRef,
+				{Properties: map[string]*Schema{"code": {Enum: enum}}},
+			},
+		}}},
+	}
+}
+
+// toOpenAPIPath /users/:idを/users/{id}に変換する
+func toOpenAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if name, ok := strings.CutPrefix(s, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, s := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(s, ":"); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// operationID メソッドとパスから一意なIDを生成する(例: get_api_v1_users_id)
+func operationID(endpoint *Endpoint) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(endpoint.Method))
+	for _, s := range strings.Split(endpoint.Path, "/") {
+		s = strings.TrimPrefix(s, ":")
+		s = strings.NewReplacer("-", "_", ".", "_").Replace(s)
+		if s != "" {
+			b.WriteString("_" + s)
+		}
+	}
+	return b.String()
+}
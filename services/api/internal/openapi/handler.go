@@ -0,0 +1,31 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// docsHTML /openapi.jsonを表示するSwagger UI
+//
+//go:embed docs.html
+var docsHTML []byte
+
+// SpecHandler ドキュメントをJSONとして返すhandler。ドキュメントは起動時に1度だけ変換する
+func SpecHandler(doc *Document) (gin.HandlerFunc, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OpenAPI document: %w", err)
+	}
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	}, nil
+}
+
+// DocsHandler /openapi.jsonを表示するドキュメントUIを返すhandler
+func DocsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsHTML)
+}
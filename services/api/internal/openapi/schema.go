@@ -0,0 +1,309 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	uuidType       = reflect.TypeFor[uuid.UUID]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// schemaRegistry structをcomponents.schemasへ登録し、$refで参照する
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	// 同名の別の型を登録しないための記録
+	types map[string]reflect.Type
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{
+		schemas: make(map[string]*Schema),
+		types:   make(map[string]reflect.Type),
+	}
+}
+
+// typeSchema 型のschemaを返す。
+// inputがtrueの場合はリクエストとして、bindingタグのrequiredを必須項目とする。
+// falseの場合はレスポンスとして、omitemptyでないフィールドを必須項目とする
+func (r *schemaRegistry) typeSchema(t reflect.Type, input bool) (*Schema, error) {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}, nil
+	case rawMessageType:
+		// 任意のJSON
+		return &Schema{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return r.typeSchema(t.Elem(), input)
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := r.typeSchema(t.Elem(), input)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		if t.Elem().Kind() == reflect.Interface {
+			return &Schema{Type: "object", AdditionalProperties: true}, nil
+		}
+		values, err := r.typeSchema(t.Elem(), input)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return r.structRef(t, input)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// structRef structをcomponents.schemasへ登録し、$refを返す
+func (r *schemaRegistry) structRef(t reflect.Type, input bool) (*Schema, error) {
+	name := t.Name()
+	if name == "" {
+		return nil, fmt.Errorf("anonymous struct %s is not supported", t)
+	}
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if registered, ok := r.types[name]; ok {
+		if registered != t {
+			return nil, fmt.Errorf("schema name %q is used by both %s and %s", name, registered, t)
+		}
+		return ref, nil
+	}
+	// 自己参照する型で無限に再帰しないよう、生成前に登録する
+	r.types[name] = t
+
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	if err := r.addFields(schema, t, input); err != nil {
+		return nil, fmt.Errorf("%s: %w", t, err)
+	}
+	r.schemas[name] = schema
+	return ref, nil
+}
+
+// addFields jsonタグに従いフィールドをpropertiesへ追加する。埋め込みstructのフィールドは展開する
+func (r *schemaRegistry) addFields(schema *Schema, t reflect.Type, input bool) error {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			if err := r.addFields(schema, field.Type, input); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema, err := r.typeSchema(field.Type, input)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		required, err := applyBinding(fieldSchema, field.Tag.Get("binding"))
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		nullable := field.Type.Kind() == reflect.Pointer || field.Type.Kind() == reflect.Map
+		if input {
+			// nullを指定した場合はnilとなり、省略と同じく扱われる
+			if nullable {
+				makeNullable(fieldSchema)
+			}
+		} else {
+			required = !strings.Contains(opts, "omitempty")
+			if required && nullable {
+				makeNullable(fieldSchema)
+			}
+		}
+
+		schema.Properties[name] = fieldSchema
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return nil
+}
+
+// makeNullable typeにnullを追加する。$refの場合は変更しない
+func makeNullable(schema *Schema) {
+	if t, ok := schema.Type.(string); ok {
+		schema.Type = []string{t, "null"}
+	}
+}
+
+// applyBinding gin(go-playground/validator)のbindingタグをschemaの制約に変換し、必須かどうかを返す。
+// 対応していないルールはドキュメントとの乖離を防ぐためエラーとする
+func applyBinding(schema *Schema, tag string) (bool, error) {
+	if tag == "" {
+		return false, nil
+	}
+	required := false
+	target := schema
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			if target == schema {
+				required = true
+			}
+		case "omitempty":
+		case "dive":
+			// 以降のルールは要素に適用する
+			if target.Items == nil {
+				return false, fmt.Errorf("dive is used for non-array type")
+			}
+			target = target.Items
+		case "min", "max":
+			if err := applyRange(target, name, param); err != nil {
+				return false, err
+			}
+		case "oneof":
+			values := strings.Fields(param)
+			target.Enum = make([]any, len(values))
+			for i, v := range values {
+				target.Enum[i] = v
+				if target.Type == "integer" {
+					n, err := strconv.Atoi(v)
+					if err != nil {
+						return false, fmt.Errorf("invalid oneof value %q for integer", v)
+					}
+					target.Enum[i] = n
+				}
+			}
+		case "email":
+			target.Format = "email"
+		case "url":
+			target.Format = "uri"
+		case "uuid":
+			target.Format = "uuid"
+		default:
+			return false, fmt.Errorf("unsupported binding rule %q", rule)
+		}
+	}
+	return required, nil
+}
+
+// applyRange min, maxを型に応じた制約に変換する
+func applyRange(schema *Schema, name, param string) error {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return fmt.Errorf("invalid %s parameter %q", name, param)
+	}
+	switch schema.Type {
+	case "string":
+		if name == "min" {
+			schema.MinLength = &n
+		} else {
+			schema.MaxLength = &n
+		}
+	case "array":
+		if name == "min" {
+			schema.MinItems = &n
+		} else {
+			schema.MaxItems = &n
+		}
+	case "integer", "number":
+		f := float64(n)
+		if name == "min" {
+			schema.Minimum = &f
+		} else {
+			schema.Maximum = &f
+		}
+	default:
+		return fmt.Errorf("%s is not supported for type %v", name, schema.Type)
+	}
+	return nil
+}
+
+// queryParameters formタグに従いqueryパラメータを生成する
+func (r *schemaRegistry) queryParameters(t reflect.Type) ([]Parameter, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("query must be a struct: %s", t)
+	}
+
+	var params []Parameter
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("form")
+		if !field.IsExported() || tag == "" || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		schema, err := r.typeSchema(field.Type, true)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t, field.Name, err)
+		}
+		required, err := applyBinding(schema, field.Tag.Get("binding"))
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t, field.Name, err)
+		}
+		if def, ok := strings.CutPrefix(opts, "default="); ok {
+			schema.Default = parseDefault(schema, def)
+		}
+
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "query",
+			Required: required,
+			Schema:   schema,
+		})
+	}
+	return params, nil
+}
+
+func parseDefault(schema *Schema, value string) any {
+	switch schema.Type {
+	case "integer":
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
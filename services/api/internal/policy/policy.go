@@ -0,0 +1,119 @@
+package policy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+// Action 権限の判定対象となる操作
+type Action string
+
+const (
+	ActionUserCreate Action = "user:create"
+	ActionUserRead   Action = "user:read"
+	// ActionUserList 一覧、メールアドレスでの検索
+	ActionUserList           Action = "user:list"
+	ActionUserUpdate         Action = "user:update"
+	ActionUserChangePassword Action = "user:change_password"
+	ActionUserDelete         Action = "user:delete"
+	ActionUserRestore        Action = "user:restore"
+	ActionUserHardDelete     Action = "user:hard_delete"
+	ActionUserChangeRole     Action = "user:change_role"
+	// ActionAuditRead 監査ログの参照
+	ActionAuditRead Action = "audit:read"
+	// ActionWebhookRead webhookの購読、配信の記録の参照
+	ActionWebhookRead Action = "webhook:read"
+	// ActionWebhookManage webhookの購読の作成・変更・削除、配信の再送
+	ActionWebhookManage Action = "webhook:manage"
+)
+
+// 操作を許可する対象の範囲
+type scope int
+
+const (
+	// scopeAny 全てのユーザーを対象に許可する
+	scopeAny scope = iota + 1
+	// scopeOwn 自分自身を対象とする場合のみ許可する
+	scopeOwn
+	// scopeNonAdmin 管理者以外のユーザーを対象とする場合のみ許可する
+	scopeNonAdmin
+)
+
+// rules roleごとに許可する操作。記載のない操作は拒否する
+var rules = map[domain.Role]map[Action]scope{
+	domain.RoleAdmin: {
+		ActionUserCreate:         scopeAny,
+		ActionUserRead:           scopeAny,
+		ActionUserList:           scopeAny,
+		ActionUserUpdate:         scopeAny,
+		ActionUserChangePassword: scopeAny,
+		ActionUserDelete:         scopeAny,
+		ActionUserRestore:        scopeAny,
+		ActionUserHardDelete:     scopeAny,
+		ActionUserChangeRole:     scopeAny,
+		ActionAuditRead:          scopeAny,
+		ActionWebhookRead:        scopeAny,
+		ActionWebhookManage:      scopeAny,
+	},
+	// 管理者のメールアドレス変更等による権限の奪取を防ぐため、管理者は操作対象としない
+	domain.RoleOperator: {
+		ActionUserCreate:         scopeAny,
+		ActionUserRead:           scopeAny,
+		ActionUserList:           scopeAny,
+		ActionUserUpdate:         scopeNonAdmin,
+		ActionUserChangePassword: scopeNonAdmin,
+		ActionUserDelete:         scopeNonAdmin,
+		ActionUserRestore:        scopeNonAdmin,
+		ActionAuditRead:          scopeAny,
+		ActionWebhookRead:        scopeAny,
+	},
+	domain.RoleViewer: {
+		ActionUserRead: scopeAny,
+		ActionUserList: scopeAny,
+	},
+	domain.RoleSelf: {
+		ActionUserRead:           scopeOwn,
+		ActionUserUpdate:         scopeOwn,
+		ActionUserChangePassword: scopeOwn,
+		ActionUserDelete:         scopeOwn,
+	},
+}
+
+// Policy contextの操作主体のroleに応じて操作の可否を判定する
+type Policy struct{}
+
+func NewPolicy() *Policy {
+	return &Policy{}
+}
+
+// Authorize 許可されない場合はErrForbiddenを返す。
+// targetは操作対象のユーザーで、作成・一覧等の対象を持たない操作ではnilを指定する
+func (p *Policy) Authorize(ctx context.Context, action Action, target *domain.User) error {
+	principal, ok := FromContext(ctx)
+	if !ok {
+		return ErrForbidden
+	}
+	s, ok := rules[principal.Role][action]
+	if !ok {
+		return ErrForbidden
+	}
+
+	switch s {
+	case scopeAny:
+		return nil
+	case scopeOwn:
+		if target != nil && principal.UserID != uuid.Nil && target.ID() == principal.UserID {
+			return nil
+		}
+	case scopeNonAdmin:
+		if target == nil || target.Role() != domain.RoleAdmin {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
@@ -0,0 +1,30 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+// Principal 認証済みの操作主体
+type Principal struct {
+	Role domain.Role
+	// アクセストークンで認証したユーザーのID。API Keyの場合はuuid.Nil
+	UserID uuid.UUID
+	// API Keyで認証した場合のキーのID。アクセストークンの場合はuuid.Nil
+	APIKeyID uuid.UUID
+}
+
+type principalKey struct{}
+
+// NewContext 認証middlewareから呼び出し、以降のusecaseで参照できるようにする
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext 認証されていない場合はfalseを返す
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
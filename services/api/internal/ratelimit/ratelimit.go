@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit Period当たりRequests回まで許可するtoken bucketの設定。
+// bucketの容量もRequestsとし、Period内であれば一度に使い切ることを許可する
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit "100/1m"の形式を解析する
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q (expected format such as \"100/1m\")", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive integer", s)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: period must be a positive duration", s)
+	}
+	return Limit{Requests: n, Period: d}, nil
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// refillInterval tokenが1つ補充されるまでの時間
+func (l Limit) refillInterval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// Result Takeの結果
+type Result struct {
+	Allowed bool
+	Limit   int
+	// 残りのtoken数
+	Remaining int
+	// bucketが満たされるまでの時間
+	ResetAfter time.Duration
+	// 拒否された場合に次のリクエストが許可されるまでの時間
+	RetryAfter time.Duration
+}
+
+// Store keyごとのbucketを保持する。複数インスタンスで共有する場合はRedis等の実装に差し替える
+type Store interface {
+	// Take keyのbucketからtokenを1つ消費する
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Config 各route向けの上限
+type Config struct {
+	Enabled bool
+	// /api/v1配下の全リクエストに適用する上限
+	Default Limit
+	// bcryptを実行するユーザー作成に適用する上限
+	CreateUser Limit
+	// パスワード変更・リセットに適用する上限。総当たりを防ぐため最も厳しくする
+	Password Limit
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+type APIKeyRepository interface {
+	// Create 同じハッシュのキーが既に存在する場合はErrAPIKeyAlreadyExistsを返す
+	Create(ctx context.Context, key *domain.APIKey) error
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	FindByKeyHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	// List 失効済みを含め作成日時の新しい順に返す
+	List(ctx context.Context) ([]*domain.APIKey, error)
+	// UpdateRevokedAt keyのrevoked_atを保存する
+	UpdateRevokedAt(ctx context.Context, key *domain.APIKey) error
+	UpdateLastUsedAt(ctx context.Context, id uuid.UUID, at time.Time) error
+}
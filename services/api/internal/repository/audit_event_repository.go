@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+type AuditEventRepository interface {
+	// Create 対象の操作と同じトランザクション内で呼び出す
+	Create(ctx context.Context, event *domain.AuditEvent) error
+	// List 発生日時の降順で返す
+	List(ctx context.Context, params AuditEventListParams) (*AuditEventListResult, error)
+}
+
+// AuditEventFilter ゼロ値の項目は条件に含めない
+type AuditEventFilter struct {
+	ActorType    domain.AuditActorType
+	ActorID      string
+	Action       domain.AuditAction
+	TargetUserID uuid.UUID
+	// 指定時刻を含む
+	From *time.Time
+	// 指定時刻を含まない
+	To *time.Time
+}
+
+// AuditEventCursor keyset paginationの位置。直前に返した行を指す
+type AuditEventCursor struct {
+	OccurredAt time.Time
+	ID         uuid.UUID
+}
+
+type AuditEventListParams struct {
+	Filter AuditEventFilter
+	Limit  int
+	// 指定された場合、このカーソルより後(古い)の行を返す
+	After *AuditEventCursor
+}
+
+type AuditEventListResult struct {
+	Events []*domain.AuditEvent
+	// 次のページが存在しない場合はnil
+	NextCursor *AuditEventCursor
+}
@@ -3,6 +3,24 @@ package repository
 import "errors"
 
 var (
-	ErrUserNotFound      = errors.New("user not found")
-	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrUserAlreadyExists    = errors.New("user already exists")
+	ErrConflict             = errors.New("resource was modified concurrently")
+	ErrInvalidSortField     = errors.New("invalid sort field")
+	ErrResetTokenNotFound   = errors.New("password reset token not found")
+	ErrAPIKeyNotFound       = errors.New("api key not found")
+	ErrAPIKeyAlreadyExists  = errors.New("api key already exists")
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	// ErrRefreshTokenAlreadyUsed 並行したリクエストにより先に使用済みにされた
+	ErrRefreshTokenAlreadyUsed = errors.New("refresh token already used")
+	ErrWebhookNotFound         = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// DBのエラーを種類ごとに分類したもの。repository実装はドライバ固有のエラーをこれらでwrapして返す
+var (
+	ErrUniqueViolation      = errors.New("unique constraint violation")
+	ErrForeignKeyViolation  = errors.New("foreign key constraint violation")
+	ErrSerializationFailure = errors.New("serialization failure")
+	ErrQueryCanceled        = errors.New("query canceled")
 )
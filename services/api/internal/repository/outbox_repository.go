@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+// OutboxRepository ドメインイベントの送信待ちの保存先。配信はservices/batchのoutbox-relayが行う
+type OutboxRepository interface {
+	// Append 対象のエンティティの書き込みと同じトランザクション内で呼び出す。イベントは引数の順に配信される
+	Append(ctx context.Context, events []domain.Event) error
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, token *domain.PasswordResetToken) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error)
+	// MarkUsed 未使用のトークンのみ使用済みにする。既に使用済みの場合はErrResetTokenNotFoundを返す
+	MarkUsed(ctx context.Context, token *domain.PasswordResetToken) error
+}
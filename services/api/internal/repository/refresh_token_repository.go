@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *domain.RefreshToken) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	// MarkUsed 未使用のトークンのみ使用済みにする。既に使用済みの場合はErrRefreshTokenAlreadyUsedを返す
+	MarkUsed(ctx context.Context, token *domain.RefreshToken) error
+	// RevokeFamily 系列の未失効のトークンを全て失効させる
+	RevokeFamily(ctx context.Context, familyID uuid.UUID, at time.Time) error
+	// RevokeAllForUser ユーザーの未失効のトークンを全て失効させる。削除・パスワード変更時等に使用する
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) error
+}
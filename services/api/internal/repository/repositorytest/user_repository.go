@@ -0,0 +1,438 @@
+// Package repositorytest repositoryの各実装が満たすべき振る舞いを検証する共通テストを提供する。
+//
+// 各実装のテストから以下のように呼び出す:
+//
+//	func TestUserRepository(t *testing.T) {
+//		repositorytest.RunUserRepositoryTests(t, func(t *testing.T) repository.UserRepository {
+//			return memory.NewUserRepository()
+//		})
+//	}
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+)
+
+// テストデータの基準時刻。PostgreSQLの精度に合わせマイクロ秒単位とする
+var baseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// RunUserRepositoryTests newRepoが返すUserRepositoryに対して共通の振る舞いを検証する。
+// newRepoはサブテストごとに呼び出され、空のrepositoryを返すこと
+func RunUserRepositoryTests(t *testing.T, newRepo func(t *testing.T) repository.UserRepository) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo repository.UserRepository)
+	}{
+		{"CreateAndFind", testCreateAndFind},
+		{"CreateDuplicateEmail", testCreateDuplicateEmail},
+		{"SoftDeleteFiltering", testSoftDeleteFiltering},
+		{"UpdateVersioning", testUpdateVersioning},
+		{"UpdateDuplicateEmail", testUpdateDuplicateEmail},
+		{"UpdatePassword", testUpdatePassword},
+		{"ListOrderingAndPagination", testListOrderingAndPagination},
+		{"ListCursor", testListCursor},
+		{"ListFilter", testListFilter},
+		{"ListInvalidSort", testListInvalidSort},
+		{"HardDelete", testHardDelete},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func testCreateAndFind(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := newUser(0, "alice@example.com", "alice")
+	mustCreate(t, repo, user)
+
+	got, err := repo.FindByID(ctx, user.ID())
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	assertSameUser(t, got, user)
+
+	got, err = repo.FindByEmail(ctx, "ALICE@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail should be case-insensitive: %v", err)
+	}
+	assertSameUser(t, got, user)
+
+	exists, err := repo.ExistsByEmail(ctx, "Alice@Example.com")
+	if err != nil {
+		t.Fatalf("ExistsByEmail: %v", err)
+	}
+	if !exists {
+		t.Error("ExistsByEmail = false, want true")
+	}
+
+	if _, err := repo.FindByID(ctx, uuid.New()); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("FindByID(unknown) error = %v, want ErrUserNotFound", err)
+	}
+	if _, err := repo.FindByEmail(ctx, "nobody@example.com"); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("FindByEmail(unknown) error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func testCreateDuplicateEmail(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	mustCreate(t, repo, newUser(0, "dup@example.com", "first"))
+
+	err := repo.Create(ctx, newUser(1, "DUP@example.com", "second"))
+	if !errors.Is(err, repository.ErrUserAlreadyExists) {
+		t.Errorf("Create(duplicate email) error = %v, want ErrUserAlreadyExists", err)
+	}
+}
+
+func testSoftDeleteFiltering(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := newUser(0, "deleted@example.com", "deleted")
+	mustCreate(t, repo, user)
+
+	user.Delete()
+	if err := repo.Update(ctx, user); err != nil {
+		t.Fatalf("Update(delete): %v", err)
+	}
+
+	if _, err := repo.FindByID(ctx, user.ID()); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("FindByID(deleted) error = %v, want ErrUserNotFound", err)
+	}
+	if _, err := repo.FindByEmail(ctx, user.Email()); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("FindByEmail(deleted) error = %v, want ErrUserNotFound", err)
+	}
+	exists, err := repo.ExistsByEmail(ctx, user.Email())
+	if err != nil {
+		t.Fatalf("ExistsByEmail: %v", err)
+	}
+	if exists {
+		t.Error("ExistsByEmail(deleted) = true, want false")
+	}
+
+	got, err := repo.FindByIDIncludingDeleted(ctx, user.ID())
+	if err != nil {
+		t.Fatalf("FindByIDIncludingDeleted: %v", err)
+	}
+	if !got.IsDeleted() {
+		t.Error("FindByIDIncludingDeleted returned user without deleted_at")
+	}
+
+	// 論理削除済みユーザーと同じメールアドレスで登録できる
+	mustCreate(t, repo, newUser(1, "deleted@example.com", "recreated"))
+}
+
+func testUpdateVersioning(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := newUser(0, "version@example.com", "version")
+	mustCreate(t, repo, user)
+
+	stale, err := repo.FindByID(ctx, user.ID())
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+
+	if err := user.UpdateUsername("renamed"); err != nil {
+		t.Fatalf("UpdateUsername: %v", err)
+	}
+	if err := user.ChangeRole(domain.RoleOperator); err != nil {
+		t.Fatalf("ChangeRole: %v", err)
+	}
+	if err := repo.Update(ctx, user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if user.Version() != 2 {
+		t.Errorf("Version after Update = %d, want 2", user.Version())
+	}
+
+	got, err := repo.FindByID(ctx, user.ID())
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.Username() != "renamed" || got.Role() != domain.RoleOperator || got.Version() != 2 {
+		t.Errorf("stored user = (%q, %q, %d), want (\"renamed\", \"operator\", 2)", got.Username(), got.Role(), got.Version())
+	}
+
+	if err := stale.UpdateUsername("stale"); err != nil {
+		t.Fatalf("UpdateUsername: %v", err)
+	}
+	if err := repo.Update(ctx, stale); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("Update(stale) error = %v, want ErrConflict", err)
+	}
+
+	missing := newUser(1, "missing@example.com", "missing")
+	if err := repo.Update(ctx, missing); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("Update(unknown) error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func testUpdateDuplicateEmail(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	mustCreate(t, repo, newUser(0, "taken@example.com", "taken"))
+	user := newUser(1, "other@example.com", "other")
+	mustCreate(t, repo, user)
+
+	if err := user.UpdateEmail("Taken@example.com"); err != nil {
+		t.Fatalf("UpdateEmail: %v", err)
+	}
+	if err := repo.Update(ctx, user); !errors.Is(err, repository.ErrUserAlreadyExists) {
+		t.Errorf("Update(duplicate email) error = %v, want ErrUserAlreadyExists", err)
+	}
+}
+
+func testUpdatePassword(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user, err := domain.NewUser("password@example.com", "password", "oldpass123")
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	mustCreate(t, repo, user)
+
+	if err := user.ChangePassword("oldpass123", "newpass456"); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if err := repo.UpdatePassword(ctx, user); err != nil {
+		t.Fatalf("UpdatePassword: %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, user.ID())
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if !got.VerifyPassword("newpass456") {
+		t.Error("stored password was not updated")
+	}
+
+	// Updateではpassword_hashを更新しない
+	other, err := domain.NewUser("ignored@example.com", "ignored", "ignored123")
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	overwrite := domain.ReconstructUser(got.ID(), got.Email(), got.Username(), other.PasswordHash(), got.Role(),
+		got.CreatedAt(), got.UpdatedAt(), got.DeletedAt(), got.Version())
+	if err := repo.Update(ctx, overwrite); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err = repo.FindByID(ctx, user.ID())
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if !got.VerifyPassword("newpass456") {
+		t.Error("Update must not change password_hash")
+	}
+
+	got.Delete()
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("Update(delete): %v", err)
+	}
+	if err := repo.UpdatePassword(ctx, got); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("UpdatePassword(deleted) error = %v, want ErrConflict", err)
+	}
+}
+
+func testListOrderingAndPagination(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	users := createUsers(t, repo, 5)
+
+	result, err := repo.List(ctx, repository.UserListParams{
+		Sort:  repository.UserSort{Field: repository.UserSortByCreatedAt, Desc: true},
+		Limit: 2,
+	})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if result.Total == nil || *result.Total != 5 {
+		t.Errorf("Total = %v, want 5", result.Total)
+	}
+	assertIDs(t, result.Users, users[4], users[3])
+	if result.NextCursor == nil {
+		t.Error("NextCursor = nil, want non-nil")
+	}
+
+	result, err = repo.List(ctx, repository.UserListParams{
+		Sort:      repository.UserSort{Field: repository.UserSortByCreatedAt, Desc: true},
+		Limit:     2,
+		Offset:    4,
+		SkipTotal: true,
+	})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if result.Total != nil {
+		t.Errorf("Total = %d, want nil when SkipTotal", *result.Total)
+	}
+	assertIDs(t, result.Users, users[0])
+	if result.NextCursor != nil {
+		t.Error("NextCursor on last page, want nil")
+	}
+
+	result, err = repo.List(ctx, repository.UserListParams{
+		Sort:  repository.UserSort{Field: repository.UserSortByUsername},
+		Limit: 10,
+	})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	assertIDs(t, result.Users, users...)
+}
+
+func testListCursor(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	users := createUsers(t, repo, 5)
+	sort := repository.UserSort{Field: repository.UserSortByCreatedAt, Desc: true}
+
+	var (
+		got   []*domain.User
+		after *repository.UserCursor
+	)
+	for range 5 {
+		result, err := repo.List(ctx, repository.UserListParams{
+			Sort:      sort,
+			Limit:     2,
+			After:     after,
+			SkipTotal: true,
+		})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		got = append(got, result.Users...)
+		if result.NextCursor == nil {
+			break
+		}
+		after = result.NextCursor
+	}
+	assertIDs(t, got, users[4], users[3], users[2], users[1], users[0])
+}
+
+func testListFilter(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	alice := newUser(0, "alice@example.com", "alice_admin")
+	bob := newUser(1, "bob@Example.com", "bob")
+	carol := newUser(2, "carol@other.org", "carol_admin")
+	deleted := newUser(3, "dave@example.com", "dave")
+	for _, u := range []*domain.User{alice, bob, carol, deleted} {
+		mustCreate(t, repo, u)
+	}
+	deleted.Delete()
+	if err := repo.Update(ctx, deleted); err != nil {
+		t.Fatalf("Update(delete): %v", err)
+	}
+
+	from := baseTime.Add(time.Minute)
+	to := baseTime.Add(3 * time.Minute)
+	tests := []struct {
+		name   string
+		filter repository.UserFilter
+		want   []*domain.User
+	}{
+		{"NoFilter", repository.UserFilter{}, []*domain.User{alice, bob, carol}},
+		{"IncludeDeleted", repository.UserFilter{IncludeDeleted: true}, []*domain.User{alice, bob, carol, deleted}},
+		{"EmailDomain", repository.UserFilter{EmailDomain: "EXAMPLE.com"}, []*domain.User{alice, bob}},
+		{"UsernamePrefix", repository.UserFilter{UsernamePrefix: "AL"}, []*domain.User{alice}},
+		{"UsernameContains", repository.UserFilter{UsernameContains: "_admin"}, []*domain.User{alice, carol}},
+		{"UsernameContainsWildcard", repository.UserFilter{UsernameContains: "%"}, nil},
+		{"CreatedRange", repository.UserFilter{CreatedFrom: &from, CreatedTo: &to, IncludeDeleted: true}, []*domain.User{bob, carol}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := repo.List(ctx, repository.UserListParams{
+				Filter: tt.filter,
+				Sort:   repository.UserSort{Field: repository.UserSortByCreatedAt},
+				Limit:  10,
+			})
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			assertIDs(t, result.Users, tt.want...)
+			if result.Total == nil || *result.Total != len(tt.want) {
+				t.Errorf("Total = %v, want %d", result.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func testListInvalidSort(t *testing.T, repo repository.UserRepository) {
+	_, err := repo.List(context.Background(), repository.UserListParams{
+		Sort:  repository.UserSort{Field: "password_hash"},
+		Limit: 10,
+	})
+	if !errors.Is(err, repository.ErrInvalidSortField) {
+		t.Errorf("List(invalid sort) error = %v, want ErrInvalidSortField", err)
+	}
+}
+
+func testHardDelete(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := newUser(0, "hard@example.com", "hard")
+	mustCreate(t, repo, user)
+	user.Delete()
+	if err := repo.Update(ctx, user); err != nil {
+		t.Fatalf("Update(delete): %v", err)
+	}
+
+	// 論理削除済みでも物理削除できる
+	if err := repo.HardDelete(ctx, user.ID()); err != nil {
+		t.Fatalf("HardDelete: %v", err)
+	}
+	if _, err := repo.FindByIDIncludingDeleted(ctx, user.ID()); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("FindByIDIncludingDeleted after HardDelete error = %v, want ErrUserNotFound", err)
+	}
+	if err := repo.HardDelete(ctx, user.ID()); !errors.Is(err, repository.ErrUserNotFound) {
+		t.Errorf("HardDelete(unknown) error = %v, want ErrUserNotFound", err)
+	}
+}
+
+// newUser baseTimeからi分後に作成されたユーザーを返す(bcryptを避けるためパスワードはダミー)
+func newUser(i int, email, username string) *domain.User {
+	createdAt := baseTime.Add(time.Duration(i) * time.Minute)
+	return domain.ReconstructUser(uuid.New(), email, username, "dummy-hash", domain.RoleSelf, createdAt, createdAt, nil, 1)
+}
+
+// createUsers 作成日時、ユーザー名ともに昇順となるn件のユーザーを作成
+func createUsers(t *testing.T, repo repository.UserRepository, n int) []*domain.User {
+	t.Helper()
+	users := make([]*domain.User, n)
+	for i := range n {
+		users[i] = newUser(i, fmt.Sprintf("user%02d@example.com", i), fmt.Sprintf("user%02d", i))
+		mustCreate(t, repo, users[i])
+	}
+	return users
+}
+
+func mustCreate(t *testing.T, repo repository.UserRepository, user *domain.User) {
+	t.Helper()
+	if err := repo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create(%s): %v", user.Email(), err)
+	}
+}
+
+func assertSameUser(t *testing.T, got, want *domain.User) {
+	t.Helper()
+	if got.ID() != want.ID() || got.Email() != want.Email() || got.Username() != want.Username() ||
+		got.Role() != want.Role() || got.Version() != want.Version() || !got.CreatedAt().Equal(want.CreatedAt()) {
+		t.Errorf("user = {%s %s %s %s v%d %s}, want {%s %s %s %s v%d %s}",
+			got.ID(), got.Email(), got.Username(), got.Role(), got.Version(), got.CreatedAt(),
+			want.ID(), want.Email(), want.Username(), want.Role(), want.Version(), want.CreatedAt())
+	}
+}
+
+func assertIDs(t *testing.T, got []*domain.User, want ...*domain.User) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d users, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].ID() != want[i].ID() {
+			t.Errorf("users[%d] = %s (%s), want %s (%s)", i, got[i].Username(), got[i].ID(), want[i].Username(), want[i].ID())
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// TxManager 複数のrepository操作を1つのトランザクションで実行する。
+// fnに渡されるctxを各repositoryに渡すと、repositoryは自動的にそのトランザクション上で処理を行う
+type TxManager interface {
+	// WithinTx fnをトランザクション内で実行する。fnがエラーを返した場合はrollbackする。
+	// serialization failureの場合はfnを再実行するため、fnはトランザクション外に副作用を持たないこと。
+	// 既にトランザクション内の場合は新たに開始せず、外側のトランザクションで実行する
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error
+}
+
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+	// serialization failure時の最大再試行回数
+	MaxRetries int
+}
+
+type TxOption func(*TxOptions)
+
+func WithIsolationLevel(level sql.IsolationLevel) TxOption {
+	return func(o *TxOptions) {
+		o.Isolation = level
+	}
+}
+
+func WithReadOnly() TxOption {
+	return func(o *TxOptions) {
+		o.ReadOnly = true
+	}
+}
+
+func WithMaxRetries(n int) TxOption {
+	return func(o *TxOptions) {
+		o.MaxRetries = n
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/tokane888/test-mcp/services/api/internal/domain"
@@ -10,8 +11,67 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
 	FindByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	// FindByIDIncludingDeleted 論理削除済みのユーザーも対象に検索する
+	FindByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*domain.User, error)
 	FindByEmail(ctx context.Context, email string) (*domain.User, error)
-	List(ctx context.Context, limit, offset int) ([]*domain.User, int, error)
+	List(ctx context.Context, params UserListParams) (*UserListResult, error)
 	Update(ctx context.Context, user *domain.User) error
+	UpdatePassword(ctx context.Context, user *domain.User) error
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	// HardDelete 行を物理削除する。論理削除済みのユーザーも対象
+	HardDelete(ctx context.Context, id uuid.UUID) error
+}
+
+// UserSortField ListUsersで指定可能なソート項目。これ以外の値はrepository実装で拒否する
+type UserSortField string
+
+const (
+	UserSortByCreatedAt UserSortField = "created_at"
+	UserSortByUpdatedAt UserSortField = "updated_at"
+	UserSortByUsername  UserSortField = "username"
+	UserSortByEmail     UserSortField = "email"
+)
+
+type UserSort struct {
+	Field UserSortField
+	Desc  bool
+}
+
+// UserFilter ListUsersの絞り込み条件。ゼロ値の項目は条件に含めない
+type UserFilter struct {
+	// メールアドレスの@以降と完全一致(大文字小文字を区別しない)
+	EmailDomain string
+	// ユーザー名の前方一致(大文字小文字を区別しない)
+	UsernamePrefix string
+	// ユーザー名の部分一致(大文字小文字を区別しない)
+	UsernameContains string
+	CreatedFrom      *time.Time
+	CreatedTo        *time.Time
+	IncludeDeleted   bool
+}
+
+// UserCursor keyset paginationの位置。Sortの並びで直前に返した行を指す
+type UserCursor struct {
+	// Sort.Fieldに対応する値。created_at, updated_atの場合はtime.Time、それ以外はstring
+	Value any
+	ID    uuid.UUID
+}
+
+type UserListParams struct {
+	Filter UserFilter
+	Sort   UserSort
+	Limit  int
+	// Afterがnilの場合のみ使用される
+	Offset int
+	// 指定された場合、このカーソルより後の行を返す
+	After *UserCursor
+	// trueの場合、COUNT(*)を実行せずUserListResult.Totalはnilとなる
+	SkipTotal bool
+}
+
+type UserListResult struct {
+	Users []*domain.User
+	Total *int
+	// 次のページが存在しない場合はnil
+	NextCursor *UserCursor
 }
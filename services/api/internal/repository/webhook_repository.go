@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+)
+
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, subscription *domain.WebhookSubscription) error
+	FindByID(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error)
+	// List 作成日時の新しい順に返す
+	List(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	Update(ctx context.Context, subscription *domain.WebhookSubscription) error
+	// Delete 配信の記録も削除する
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type WebhookDeliveryRepository interface {
+	// FindByID 購読に属さない配信の場合はErrWebhookDeliveryNotFoundを返す
+	FindByID(ctx context.Context, subscriptionID, id uuid.UUID) (*domain.WebhookDelivery, error)
+	// List 作成日時の新しい順に返す
+	List(ctx context.Context, params WebhookDeliveryListParams) (*WebhookDeliveryListResult, error)
+	// ListAttempts 送信日時の新しい順に返す
+	ListAttempts(ctx context.Context, deliveryID uuid.UUID) ([]domain.WebhookDeliveryAttempt, error)
+	// Replay 読み込み時から状態が変わっていない場合のみ再送待ちとして保存する。変わっていた場合はErrConflictを返す
+	Replay(ctx context.Context, delivery *domain.WebhookDelivery, previousStatus domain.WebhookDeliveryStatus) error
+}
+
+// WebhookDeliveryCursor keyset paginationの位置。直前に返した行を指す
+type WebhookDeliveryCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+type WebhookDeliveryListParams struct {
+	SubscriptionID uuid.UUID
+	// 空の場合は全ての状態
+	Status domain.WebhookDeliveryStatus
+	Limit  int
+	// 指定された場合、このカーソルより後(古い)の行を返す
+	After *WebhookDeliveryCursor
+}
+
+type WebhookDeliveryListResult struct {
+	Deliveries []*domain.WebhookDelivery
+	// 次のページが存在しない場合はnil
+	NextCursor *WebhookDeliveryCursor
+}
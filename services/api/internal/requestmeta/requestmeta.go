@@ -0,0 +1,21 @@
+// Package requestmeta HTTPリクエストの付帯情報をcontext経由でusecase層へ渡す
+package requestmeta
+
+import "context"
+
+type Metadata struct {
+	RequestID string
+	ClientIP  string
+}
+
+type metadataKey struct{}
+
+func NewContext(ctx context.Context, m Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, m)
+}
+
+// FromContext HTTPリクエスト以外(起動時の処理等)から呼び出された場合はゼロ値を返す
+func FromContext(ctx context.Context) Metadata {
+	m, _ := ctx.Value(metadataKey{}).(Metadata)
+	return m
+}
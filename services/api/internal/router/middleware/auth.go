@@ -1,14 +1,30 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
-	"os"
 
 	"github.com/gin-gonic/gin"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
 	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+	"github.com/tokane888/test-mcp/services/api/internal/policy"
+	"github.com/tokane888/test-mcp/services/api/internal/usecase"
+	"go.uber.org/zap"
 )
 
-func APIKeyAuth() gin.HandlerFunc {
+// 認証済みのAPI Keyを保持するgin.Contextのキー
+const apiKeyContextKey = "api_key"
+
+// APIKeyAuthenticator usecase.APIKeyUseCaseが満たす
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, plain string) (*domain.APIKey, error)
+}
+
+// APIKeyAuth X-API-Keyを検証し、認証したキーをcontextに保持する。
+// 操作ごとの権限はRequireScopeで確認する
+func APIKeyAuth(authenticator APIKeyAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		apiKey := c.GetHeader("X-API-Key")
 		if apiKey == "" {
@@ -17,20 +33,59 @@ func APIKeyAuth() gin.HandlerFunc {
 			return
 		}
 
-		expectedKey := os.Getenv("API_KEY")
-		if expectedKey == "" {
-			// 環境変数が設定されていない場合はエラー
-			c.JSON(http.StatusInternalServerError, response.NewError("CONFIG_ERROR", "API Key設定エラー"))
+		key, err := authenticator.Authenticate(c.Request.Context(), apiKey)
+		if err != nil {
+			if errors.Is(err, usecase.ErrInvalidAPIKey) {
+				c.JSON(http.StatusUnauthorized, response.NewError("INVALID_API_KEY", "無効なAPI Keyです"))
+				c.Abort()
+				return
+			}
+			pkglogger.FromContext(c.Request.Context()).Error("failed to authenticate api key", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
 			c.Abort()
 			return
 		}
 
-		if apiKey != expectedKey {
-			c.JSON(http.StatusUnauthorized, response.NewError("INVALID_API_KEY", "無効なAPI Keyです"))
+		c.Set(apiKeyContextKey, key)
+		// usecaseのpolicyで操作の可否を判定するため、request contextにも保持する
+		c.Request = c.Request.WithContext(policy.NewContext(c.Request.Context(), &policy.Principal{
+			Role:     key.Role(),
+			APIKeyID: key.ID(),
+		}))
+		setLogFields(c,
+			zap.String("user", key.Owner()),
+			zap.String("api_key_id", key.ID().String()),
+			zap.String("api_key_name", key.Name()),
+			zap.String("role", string(key.Role())),
+		)
+		c.Next()
+	}
+}
+
+// RequireScope 認証済みのAPI Keyがscopeを持つ場合のみ許可する。APIKeyAuthより後に適用する
+func RequireScope(scope domain.APIKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, ok := APIKeyFromContext(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, response.NewError("MISSING_API_KEY", "API Keyが指定されていません"))
+			c.Abort()
+			return
+		}
+		if !key.HasScope(scope) {
+			c.JSON(http.StatusForbidden, response.NewError("INSUFFICIENT_SCOPE", "この操作を行う権限がありません"))
 			c.Abort()
 			return
 		}
-
 		c.Next()
 	}
 }
+
+// APIKeyFromContext APIKeyAuthで認証したキーを返す
+func APIKeyFromContext(c *gin.Context) (*domain.APIKey, bool) {
+	v, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return nil, false
+	}
+	key, ok := v.(*domain.APIKey)
+	return key, ok
+}
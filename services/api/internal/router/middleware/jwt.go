@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/auth"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+	"github.com/tokane888/test-mcp/services/api/internal/policy"
+	"go.uber.org/zap"
+)
+
+// 認証済みのユーザーIDを保持するgin.Contextのキー
+const userIDContextKey = "user_id"
+
+// AccessTokenVerifier auth.Managerが満たす
+type AccessTokenVerifier interface {
+	VerifyAccessToken(token string) (*auth.Claims, error)
+}
+
+// JWTAuth Authorization: Bearerのアクセストークンを検証し、ユーザーIDをcontextに保持する。
+// エンドユーザー向けのroute groupに適用し、API Key認証とは併用しない
+func JWTAuth(verifier AccessTokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			c.Header("WWW-Authenticate", `Bearer`)
+			c.JSON(http.StatusUnauthorized, response.NewError("MISSING_ACCESS_TOKEN", "アクセストークンが指定されていません"))
+			c.Abort()
+			return
+		}
+
+		claims, err := verifier.VerifyAccessToken(token)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidToken) {
+				pkglogger.FromContext(c.Request.Context()).Debug("invalid access token", zap.Error(err))
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+				c.JSON(http.StatusUnauthorized, response.NewError("INVALID_ACCESS_TOKEN", "無効なアクセストークンです"))
+				c.Abort()
+				return
+			}
+			pkglogger.FromContext(c.Request.Context()).Error("failed to verify access token", zap.Error(err))
+			c.JSON(http.StatusInternalServerError, response.NewError("INTERNAL_ERROR", "内部エラーが発生しました"))
+			c.Abort()
+			return
+		}
+
+		c.Set(userIDContextKey, claims.UserID)
+		c.Request = c.Request.WithContext(policy.NewContext(c.Request.Context(), &policy.Principal{
+			Role:   claims.Role,
+			UserID: claims.UserID,
+		}))
+		setLogFields(c,
+			zap.String("user", claims.UserID.String()),
+			zap.String("role", string(claims.Role)),
+		)
+		c.Next()
+	}
+}
+
+// UserIDFromContext JWTAuthで認証したユーザーのIDを返す
+func UserIDFromContext(c *gin.Context) (uuid.UUID, bool) {
+	v, ok := c.Get(userIDContextKey)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, ok := v.(uuid.UUID)
+	return id, ok
+}
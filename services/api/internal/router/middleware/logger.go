@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
 	"go.uber.org/zap"
 )
 
@@ -28,7 +29,8 @@ func (w responseWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
-func Logger(logger *zap.Logger) gin.HandlerFunc {
+// Logger アクセスログを出力する。request_id等を含めるためRequestIDより内側に置く
+func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
@@ -75,6 +77,7 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 		}
 
 		// ログレベルの決定
+		logger := pkglogger.FromContext(c.Request.Context())
 		switch {
 		case c.Writer.Status() >= 500:
 			logger.Error("request failed", fields...)
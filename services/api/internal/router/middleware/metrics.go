@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HTTPMetrics *metrics.Metricsが満たす
+type HTTPMetrics interface {
+	ObserveHTTPRequest(route, method string, status int, duration time.Duration)
+}
+
+// Metrics リクエスト数、レイテンシをroute template単位で記録する
+func Metrics(m HTTPMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		m.ObserveHTTPRequest(c.FullPath(), c.Request.Method, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+	"github.com/tokane888/test-mcp/services/api/internal/ratelimit"
+	"go.uber.org/zap"
+)
+
+// RateLimit クライアント単位でリクエスト数を制限する。nameごとに別のbucketを使用するため、
+// グループ全体の上限とrouteごとの上限を重ねて適用できる。
+// RateLimit-*ヘッダーは内側(後に実行される)の上限で上書きされる
+func RateLimit(store ratelimit.Store, name string, limit ratelimit.Limit) gin.HandlerFunc {
+	policy := strconv.Itoa(limit.Requests) + ";w=" + strconv.Itoa(int(math.Ceil(limit.Period.Seconds())))
+	return func(c *gin.Context) {
+		result, err := store.Take(c.Request.Context(), name+":"+clientKey(c), limit)
+		if err != nil {
+			// storeの障害でAPI全体を停止させないよう制限せずに処理を継続する
+			pkglogger.FromContext(c.Request.Context()).Warn("rate limit store unavailable, allowing request",
+				zap.String("limit", name), zap.Error(err))
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.JSON(http.StatusTooManyRequests, response.NewError("RATE_LIMIT_EXCEEDED", "リクエスト数が上限を超えました。しばらく待ってから再試行してください"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// clientKey 認証済みのAPI Key、またはユーザーを制限の単位とする。認証前のrouteではクライアントのIPを単位とする。
+// 未検証のheaderを単位にするとリクエストごとに値を変えて制限を回避できるため、認証より後に適用すること
+func clientKey(c *gin.Context) string {
+	if key, ok := APIKeyFromContext(c); ok {
+		return "key:" + key.ID().String()
+	}
+	if userID, ok := UserIDFromContext(c); ok {
+		return "user:" + userID.String()
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
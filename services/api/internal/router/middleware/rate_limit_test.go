@@ -0,0 +1,34 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tokane888/test-mcp/services/api/internal/ratelimit"
+	"github.com/tokane888/test-mcp/services/api/internal/router/middleware"
+)
+
+// 認証していないX-API-Keyを変えても、同じクライアントは同じbucketで制限される
+func TestRateLimitIgnoresUnverifiedAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	limit := ratelimit.Limit{Requests: 2, Period: time.Minute}
+	engine.POST("/login", middleware.RateLimit(ratelimit.NewMemoryStore(), "login", limit), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	want := []int{http.StatusNoContent, http.StatusNoContent, http.StatusTooManyRequests}
+	for i, status := range want {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.Header.Set("X-API-Key", "random-"+strconv.Itoa(i))
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		if rec.Code != status {
+			t.Errorf("request %d: status = %d, want %d", i, rec.Code, status)
+		}
+	}
+}
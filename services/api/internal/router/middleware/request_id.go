@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/requestmeta"
+	"github.com/tokane888/test-mcp/services/api/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// クライアントから受け取るrequest IDの形式。ログの改ざんや肥大化を防ぐため、満たさない場合は新たに生成する
+var requestIDRegex = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID X-Request-IDを引き継ぐか生成してレスポンスヘッダーに付与し、
+// request_id, route, trace_id等を付与したloggerとrequest IDをcontextに保持する。
+// 以降の各層ではpkglogger.FromContextで取得したloggerを使用する
+func RequestID(logger *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDRegex.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := c.Request.Context()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request_id", requestID))
+
+		fields := []zap.Field{
+			zap.String("request_id", requestID),
+			zap.String("route", c.FullPath()),
+		}
+		fields = append(fields, tracing.LogFields(ctx)...)
+		ctx = requestmeta.NewContext(ctx, requestmeta.Metadata{
+			RequestID: requestID,
+			ClientIP:  c.ClientIP(),
+		})
+		c.Request = c.Request.WithContext(pkglogger.NewContext(ctx, logger.With(fields...)))
+
+		c.Next()
+	}
+}
+
+// setLogFields 以降の処理で使用するloggerにfieldsを追加する
+func setLogFields(c *gin.Context, fields ...zap.Field) {
+	c.Request = c.Request.WithContext(pkglogger.WithFields(c.Request.Context(), fields...))
+}
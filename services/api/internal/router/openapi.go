@@ -0,0 +1,380 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/tokane888/test-mcp/services/api/internal/auth"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/query"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/response"
+	"github.com/tokane888/test-mcp/services/api/internal/health"
+	"github.com/tokane888/test-mcp/services/api/internal/openapi"
+)
+
+const (
+	openAPIPath = "/openapi.json"
+	docsPath    = "/docs"
+)
+
+var openAPIInfo = openapi.Info{
+	Title:       "User Management API",
+	Version:     "1.0.0",
+	Description: "エラー時はresponse.Errorを返す。各レスポンスの説明にはステータスごとに返し得るcodeを記載する",
+}
+
+var openAPITags = []openapi.Tag{
+	{Name: "health", Description: "死活監視"},
+	{Name: "auth", Description: "エンドユーザーの認証"},
+	{Name: "me", Description: "認証したエンドユーザー自身の操作"},
+	{Name: "users", Description: "ユーザー管理"},
+	{Name: "password-reset", Description: "パスワードリセット"},
+	{Name: "audit", Description: "監査ログ"},
+	{Name: "webhooks", Description: "webhookの購読管理"},
+	{Name: "admin", Description: "管理者向けの操作"},
+}
+
+// handlerが返すエラー
+var (
+	errForbidden         = openapi.ErrorCase{Status: http.StatusForbidden, Code: "FORBIDDEN"}
+	errInvalidCursor     = openapi.ErrorCase{Status: http.StatusBadRequest, Code: "INVALID_CURSOR"}
+	errUserNotFound      = openapi.ErrorCase{Status: http.StatusNotFound, Code: "USER_NOT_FOUND"}
+	errUserAlreadyExists = openapi.ErrorCase{Status: http.StatusConflict, Code: "USER_ALREADY_EXISTS"}
+	errConflict          = openapi.ErrorCase{Status: http.StatusConflict, Code: "CONFLICT"}
+	errWebhookNotFound   = openapi.ErrorCase{Status: http.StatusNotFound, Code: "WEBHOOK_NOT_FOUND"}
+	errDeliveryNotFound  = openapi.ErrorCase{Status: http.StatusNotFound, Code: "WEBHOOK_DELIVERY_NOT_FOUND"}
+	errAPIKeyNotFound    = openapi.ErrorCase{Status: http.StatusNotFound, Code: "API_KEY_NOT_FOUND"}
+	errAPIKeyRevoked     = openapi.ErrorCase{Status: http.StatusConflict, Code: "API_KEY_REVOKED"}
+	errAPIKeyExpired     = openapi.ErrorCase{Status: http.StatusConflict, Code: "API_KEY_EXPIRED"}
+
+	// ドメインのバリデーションエラー(handler.writeDomainError)
+	userValidationErrors = []openapi.ErrorCase{
+		{Status: http.StatusBadRequest, Code: "INVALID_EMAIL"},
+		{Status: http.StatusBadRequest, Code: "PASSWORD_TOO_SHORT"},
+		{Status: http.StatusBadRequest, Code: "USERNAME_TOO_SHORT"},
+		{Status: http.StatusBadRequest, Code: "USERNAME_TOO_LONG"},
+		{Status: http.StatusBadRequest, Code: "INVALID_PASSWORD_FORMAT"},
+	}
+	webhookValidationErrors = []openapi.ErrorCase{
+		{Status: http.StatusBadRequest, Code: "INVALID_WEBHOOK_URL"},
+		{Status: http.StatusBadRequest, Code: "INVALID_EVENT_TYPE"},
+	}
+)
+
+// OpenAPIDocument 登録するrouteのOpenAPIドキュメントを生成する
+func OpenAPIDocument() (*openapi.Document, error) {
+	return openapi.Generate(openAPIInfo, openAPITags, endpoints())
+}
+
+// endpoints Setupで登録するrouteの定義。routeを追加、変更した場合はここも更新すること。
+// 登録したrouteとの一致はopenapi_test.goで確認する
+func endpoints() []openapi.Endpoint {
+	readUsers := string(domain.ScopeUsersRead)
+	writeUsers := string(domain.ScopeUsersWrite)
+	admin := string(domain.ScopeAdmin)
+
+	return []openapi.Endpoint{
+		// ヘルスチェック
+		{
+			Method: http.MethodGet, Path: "/health", Tag: "health",
+			Summary:     "ヘルスチェック(非推奨)",
+			Description: "後方互換のため残している。依存先の状態は確認しないため、監視には/livez, /readyzを使用すること",
+			Success:     openapi.Success{Status: http.StatusOK, Body: map[string]string{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/livez", Tag: "health",
+			Summary: "liveness",
+			Success: openapi.Success{Status: http.StatusOK, Body: health.Report{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/readyz", Tag: "health",
+			Summary:     "readiness",
+			Description: "DB等の依存先を含め、リクエストを受け付けられる状態かを返す。失敗時は503とともに同じ形式の結果を返す",
+			Success:     openapi.Success{Status: http.StatusOK, Body: health.Report{}},
+		},
+		{
+			Method: http.MethodGet, Path: "/.well-known/jwks.json", Tag: "auth",
+			Summary: "アクセストークンの検証用の公開鍵",
+			Success: openapi.Success{Status: http.StatusOK, Body: auth.JWKS{}},
+		},
+
+		// エンドユーザーの認証
+		{
+			Method: http.MethodPost, Path: "/api/v1/auth/login", Tag: "auth",
+			Summary:     "ログイン",
+			RateLimited: true,
+			Body:        request.Login{},
+			Success:     openapi.Success{Status: http.StatusOK, Body: response.TokenPair{}},
+			Errors:      []openapi.ErrorCase{{Status: http.StatusUnauthorized, Code: "INVALID_CREDENTIALS"}},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/auth/refresh", Tag: "auth",
+			Summary:     "アクセストークンの再発行",
+			Description: "リフレッシュトークンは1回のみ使用でき、新しいリフレッシュトークンを返す",
+			RateLimited: true,
+			Body:        request.RefreshToken{},
+			Success:     openapi.Success{Status: http.StatusOK, Body: response.TokenPair{}},
+			Errors:      []openapi.ErrorCase{{Status: http.StatusUnauthorized, Code: "INVALID_REFRESH_TOKEN"}},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/auth/logout", Tag: "auth",
+			Summary:     "ログアウト",
+			RateLimited: true,
+			Body:        request.RefreshToken{},
+			Success:     openapi.Success{Status: http.StatusNoContent},
+		},
+
+		// エンドユーザー自身の操作
+		{
+			Method: http.MethodGet, Path: "/api/v1/me", Tag: "me",
+			Summary: "自身の情報の取得",
+			Auth:    openapi.AuthBearer, RateLimited: true,
+			Success: openapi.Success{Status: http.StatusOK, Body: response.User{}, ETag: true},
+			Errors:  []openapi.ErrorCase{errForbidden},
+		},
+		{
+			Method: http.MethodPatch, Path: "/api/v1/me", Tag: "me",
+			Summary: "自身の情報の更新",
+			Auth:    openapi.AuthBearer, RateLimited: true, IfMatch: true,
+			Body:    request.UpdateUser{},
+			Success: openapi.Success{Status: http.StatusOK, Body: response.User{}, ETag: true},
+			Errors:  append([]openapi.ErrorCase{errForbidden, errUserNotFound, errUserAlreadyExists, errConflict}, userValidationErrors...),
+		},
+		{
+			Method: http.MethodPut, Path: "/api/v1/me/password", Tag: "me",
+			Summary: "自身のパスワードの変更",
+			Auth:    openapi.AuthBearer, RateLimited: true,
+			Body:    request.ChangePassword{},
+			Success: openapi.Success{Status: http.StatusNoContent},
+			Errors: append([]openapi.ErrorCase{
+				errForbidden, errUserNotFound, errConflict,
+				{Status: http.StatusBadRequest, Code: "INCORRECT_PASSWORD"},
+			}, userValidationErrors...),
+		},
+
+		// ユーザー管理
+		{
+			Method: http.MethodPost, Path: "/api/v1/users", Tag: "users",
+			Summary: "ユーザーの作成",
+			Auth:    openapi.AuthAPIKey, Scope: writeUsers, RateLimited: true,
+			Body:    request.CreateUser{},
+			Success: openapi.Success{Status: http.StatusCreated, Body: response.User{}, ETag: true},
+			Errors:  append([]openapi.ErrorCase{errForbidden, errUserAlreadyExists}, userValidationErrors...),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/users", Tag: "users",
+			Summary:     "ユーザーの一覧",
+			Description: "cursor指定時はkeyset pagination、未指定時はoffset paginationとなる",
+			Auth:        openapi.AuthAPIKey, Scope: readUsers, RateLimited: true,
+			Query:   query.ListUsers{},
+			Success: openapi.Success{Status: http.StatusOK, Body: response.UserList{}},
+			Errors: []openapi.ErrorCase{
+				errForbidden, errInvalidCursor,
+				{Status: http.StatusBadRequest, Code: "INVALID_SORT"},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/users/by-email", Tag: "users",
+			Summary: "メールアドレスによるユーザーの取得",
+			Auth:    openapi.AuthAPIKey, Scope: readUsers, RateLimited: true,
+			Query:   query.GetUserByEmail{},
+			Success: openapi.Success{Status: http.StatusOK, Body: response.User{}, ETag: true},
+			Errors:  []openapi.ErrorCase{errForbidden, errUserNotFound},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/users/:id", Tag: "users",
+			Summary: "ユーザーの取得",
+			Auth:    openapi.AuthAPIKey, Scope: readUsers, RateLimited: true,
+			Success: openapi.Success{Status: http.StatusOK, Body: response.User{}, ETag: true},
+			Errors:  []openapi.ErrorCase{errForbidden, errUserNotFound},
+		},
+		{
+			Method: http.MethodPatch, Path: "/api/v1/users/:id", Tag: "users",
+			Summary: "ユーザーの更新",
+			Auth:    openapi.AuthAPIKey, Scope: writeUsers, RateLimited: true, IfMatch: true,
+			Body:    request.UpdateUser{},
+			Success: openapi.Success{Status: http.StatusOK, Body: response.User{}, ETag: true},
+			Errors:  append([]openapi.ErrorCase{errForbidden, errUserNotFound, errUserAlreadyExists, errConflict}, userValidationErrors...),
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/v1/users/:id", Tag: "users",
+			Summary: "ユーザーの論理削除",
+			Auth:    openapi.AuthAPIKey, Scope: writeUsers, RateLimited: true, IfMatch: true,
+			Success: openapi.Success{Status: http.StatusNoContent},
+			Errors:  []openapi.ErrorCase{errForbidden, errUserNotFound, errConflict},
+		},
+		{
+			Method: http.MethodPut, Path: "/api/v1/users/:id/password", Tag: "users",
+			Summary: "パスワードの変更",
+			Auth:    openapi.AuthAPIKey, Scope: writeUsers, RateLimited: true,
+			Body:    request.ChangePassword{},
+			Success: openapi.Success{Status: http.StatusNoContent},
+			Errors: append([]openapi.ErrorCase{
+				errForbidden, errUserNotFound, errConflict,
+				{Status: http.StatusBadRequest, Code: "INCORRECT_PASSWORD"},
+			}, userValidationErrors...),
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/users/:id/restore", Tag: "users",
+			Summary: "論理削除したユーザーの復元",
+			Auth:    openapi.AuthAPIKey, Scope: writeUsers, RateLimited: true, IfMatch: true,
+			Success: openapi.Success{Status: http.StatusOK, Body: response.User{}, ETag: true},
+			Errors: []openapi.ErrorCase{
+				errForbidden, errUserNotFound, errUserAlreadyExists, errConflict,
+				{Status: http.StatusConflict, Code: "USER_NOT_DELETED"},
+			},
+		},
+
+		// パスワードリセット
+		{
+			Method: http.MethodPost, Path: "/api/v1/password-reset", Tag: "password-reset",
+			Summary: "リセットトークンの発行",
+			Auth:    openapi.AuthAPIKey, Scope: writeUsers, RateLimited: true,
+			Body:    request.RequestPasswordReset{},
+			Success: openapi.Success{Status: http.StatusCreated, Body: response.PasswordResetToken{}},
+			Errors:  []openapi.ErrorCase{errUserNotFound, errForbidden},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/password-reset/confirm", Tag: "password-reset",
+			Summary: "リセットトークンによるパスワードの再設定",
+			Auth:    openapi.AuthAPIKey, Scope: writeUsers, RateLimited: true,
+			Body:    request.ConfirmPasswordReset{},
+			Success: openapi.Success{Status: http.StatusNoContent},
+			Errors: append([]openapi.ErrorCase{
+				errForbidden,
+				errConflict,
+				{Status: http.StatusBadRequest, Code: "INVALID_RESET_TOKEN"},
+			}, userValidationErrors...),
+		},
+
+		// 監査ログ
+		{
+			Method: http.MethodGet, Path: "/api/v1/audit", Tag: "audit",
+			Summary:     "監査ログの一覧",
+			Description: "発生日時の新しい順に返す。admin, operatorのroleのみ閲覧できる",
+			Auth:        openapi.AuthAPIKey, Scope: readUsers, RateLimited: true,
+			Query:   query.ListAuditEvents{},
+			Success: openapi.Success{Status: http.StatusOK, Body: response.AuditEventList{}},
+			Errors:  []openapi.ErrorCase{errForbidden, errInvalidCursor},
+		},
+
+		// webhook
+		{
+			Method: http.MethodPost, Path: "/api/v1/webhooks", Tag: "webhooks",
+			Summary:     "webhookの作成",
+			Description: "署名用のシークレットを返すのは作成時のみ。adminのroleのみ操作できる",
+			Auth:        openapi.AuthAPIKey, Scope: writeUsers, RateLimited: true,
+			Body:    request.CreateWebhook{},
+			Success: openapi.Success{Status: http.StatusCreated, Body: response.CreatedWebhookSubscription{}},
+			Errors:  append([]openapi.ErrorCase{errForbidden}, webhookValidationErrors...),
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/webhooks", Tag: "webhooks",
+			Summary: "webhookの一覧",
+			Auth:    openapi.AuthAPIKey, Scope: readUsers, RateLimited: true,
+			Success: openapi.Success{Status: http.StatusOK, Body: response.WebhookSubscriptionList{}},
+			Errors:  []openapi.ErrorCase{errForbidden},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/webhooks/:id", Tag: "webhooks",
+			Summary: "webhookの取得",
+			Auth:    openapi.AuthAPIKey, Scope: readUsers, RateLimited: true,
+			Success: openapi.Success{Status: http.StatusOK, Body: response.WebhookSubscription{}},
+			Errors:  []openapi.ErrorCase{errForbidden, errWebhookNotFound},
+		},
+		{
+			Method: http.MethodPatch, Path: "/api/v1/webhooks/:id", Tag: "webhooks",
+			Summary: "webhookの更新",
+			Auth:    openapi.AuthAPIKey, Scope: writeUsers, RateLimited: true,
+			Body:    request.UpdateWebhook{},
+			Success: openapi.Success{Status: http.StatusOK, Body: response.WebhookSubscription{}},
+			Errors:  append([]openapi.ErrorCase{errForbidden, errWebhookNotFound}, webhookValidationErrors...),
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/v1/webhooks/:id", Tag: "webhooks",
+			Summary: "webhookの削除",
+			Auth:    openapi.AuthAPIKey, Scope: writeUsers, RateLimited: true,
+			Success: openapi.Success{Status: http.StatusNoContent},
+			Errors:  []openapi.ErrorCase{errForbidden, errWebhookNotFound},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/webhooks/:id/deliveries", Tag: "webhooks",
+			Summary:     "配信の一覧",
+			Description: "作成日時の新しい順に返す",
+			Auth:        openapi.AuthAPIKey, Scope: readUsers, RateLimited: true,
+			Query:   query.ListWebhookDeliveries{},
+			Success: openapi.Success{Status: http.StatusOK, Body: response.WebhookDeliveryList{}},
+			Errors:  []openapi.ErrorCase{errForbidden, errWebhookNotFound, errInvalidCursor},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/webhooks/:id/deliveries/:delivery_id", Tag: "webhooks",
+			Summary: "配信の取得",
+			Auth:    openapi.AuthAPIKey, Scope: readUsers, RateLimited: true,
+			Success: openapi.Success{Status: http.StatusOK, Body: response.WebhookDeliveryDetail{}},
+			Errors:  []openapi.ErrorCase{errForbidden, errDeliveryNotFound},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/webhooks/:id/deliveries/:delivery_id/replay", Tag: "webhooks",
+			Summary:     "配信の再送",
+			Description: "succeeded, deadの配信を再送待ちに戻す。送信はwebhook-dispatcherが非同期に行う",
+			Auth:        openapi.AuthAPIKey, Scope: writeUsers, RateLimited: true,
+			Success: openapi.Success{Status: http.StatusAccepted, Body: response.WebhookDelivery{}},
+			Errors: []openapi.ErrorCase{
+				errForbidden, errDeliveryNotFound, errConflict,
+				{Status: http.StatusConflict, Code: "WEBHOOK_DELIVERY_PENDING"},
+			},
+		},
+
+		// 管理者向け
+		{
+			Method: http.MethodDelete, Path: "/api/v1/admin/users/:id", Tag: "admin",
+			Summary: "ユーザーの物理削除",
+			Auth:    openapi.AuthAPIKey, Scope: admin, RateLimited: true,
+			Success: openapi.Success{Status: http.StatusNoContent},
+			Errors:  []openapi.ErrorCase{errForbidden, errUserNotFound},
+		},
+		{
+			Method: http.MethodPut, Path: "/api/v1/admin/users/:id/role", Tag: "admin",
+			Summary: "ユーザーのroleの変更",
+			Auth:    openapi.AuthAPIKey, Scope: admin, RateLimited: true, IfMatch: true,
+			Body:    request.ChangeRole{},
+			Success: openapi.Success{Status: http.StatusOK, Body: response.User{}, ETag: true},
+			Errors: []openapi.ErrorCase{
+				errForbidden, errUserNotFound, errConflict,
+				{Status: http.StatusBadRequest, Code: "INVALID_ROLE"},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/admin/api-keys", Tag: "admin",
+			Summary:     "API Keyの発行",
+			Description: "平文のキーを返すのは発行時のみ。操作主体より上位のroleのキーは発行できない",
+			Auth:        openapi.AuthAPIKey, Scope: admin, RateLimited: true,
+			Body:    request.IssueAPIKey{},
+			Success: openapi.Success{Status: http.StatusCreated, Body: response.IssuedAPIKey{}},
+			Errors:  []openapi.ErrorCase{errForbidden},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/admin/api-keys", Tag: "admin",
+			Summary: "API Keyの一覧",
+			Auth:    openapi.AuthAPIKey, Scope: admin, RateLimited: true,
+			Success: openapi.Success{Status: http.StatusOK, Body: response.APIKeyList{}},
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/v1/admin/api-keys/:id", Tag: "admin",
+			Summary: "API Keyの失効",
+			Auth:    openapi.AuthAPIKey, Scope: admin, RateLimited: true,
+			Success: openapi.Success{Status: http.StatusOK, Body: response.APIKey{}},
+			Errors:  []openapi.ErrorCase{errAPIKeyNotFound, errAPIKeyRevoked},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/admin/api-keys/:id/rotate", Tag: "admin",
+			Summary:     "API Keyのrotate",
+			Description: "新しいキーを発行し、旧キーをgrace_period_seconds後に失効させる。期限切れのキーはrotateできない",
+			Auth:        openapi.AuthAPIKey, Scope: admin, RateLimited: true,
+			Body:         request.RotateAPIKey{},
+			BodyOptional: true,
+			Success:      openapi.Success{Status: http.StatusCreated, Body: response.IssuedAPIKey{}},
+			Errors:       []openapi.ErrorCase{errForbidden, errAPIKeyNotFound, errAPIKeyRevoked, errAPIKeyExpired},
+		},
+	}
+}
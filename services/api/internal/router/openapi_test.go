@@ -0,0 +1,169 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/auth"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/handler"
+	"github.com/tokane888/test-mcp/services/api/internal/openapi"
+	"github.com/tokane888/test-mcp/services/api/internal/ratelimit"
+	"go.uber.org/zap"
+)
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveHTTPRequest(string, string, int, time.Duration) {}
+
+// stubAPIKeys 平文のAPI Keyをカンマ区切りのscopeとして扱う
+type stubAPIKeys struct{}
+
+func (stubAPIKeys) Authenticate(_ context.Context, plain string) (*domain.APIKey, error) {
+	var scopes []domain.APIKeyScope
+	for _, scope := range strings.Split(plain, ",") {
+		if scope != "" && scope != "none" {
+			scopes = append(scopes, domain.APIKeyScope(scope))
+		}
+	}
+	return domain.ReconstructAPIKey(uuid.New(), "test", "test", "test", "", scopes, domain.RoleAdmin, nil, nil, nil, time.Now()), nil
+}
+
+type stubTokens struct{}
+
+func (stubTokens) VerifyAccessToken(string) (*auth.Claims, error) {
+	return &auth.Claims{UserID: uuid.New(), Role: domain.RoleSelf, ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+// newTestEngine handlerの依存先なしでrouteを登録する。
+// handlerまで到達したリクエストはpanicし、Recoveryにより500となる
+func newTestEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	gin.DefaultErrorWriter = io.Discard
+	t.Cleanup(func() { gin.DefaultErrorWriter = nil })
+
+	limit := ratelimit.Limit{Requests: 1000, Period: time.Minute}
+	config := &Config{RateLimit: ratelimit.Config{Enabled: true, Default: limit, CreateUser: limit, Password: limit}}
+	r := NewRouter(config, zap.NewNop(), &handler.Handler{}, nopMetrics{}, ratelimit.NewMemoryStore(), stubAPIKeys{}, stubTokens{})
+	engine, err := r.Setup()
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	return engine
+}
+
+// 登録したrouteとOpenAPIドキュメントの定義が1対1で対応する
+func TestEndpointsMatchRoutes(t *testing.T) {
+	engine := newTestEngine(t)
+
+	var registered, defined []string
+	for _, route := range engine.Routes() {
+		if route.Path == openAPIPath || route.Path == docsPath {
+			continue
+		}
+		registered = append(registered, route.Method+" "+route.Path)
+	}
+	for _, e := range endpoints() {
+		defined = append(defined, e.Method+" "+e.Path)
+	}
+
+	for _, route := range registered {
+		if !slices.Contains(defined, route) {
+			t.Errorf("route without OpenAPI definition: %s", route)
+		}
+	}
+	for _, endpoint := range defined {
+		if !slices.Contains(registered, endpoint) {
+			t.Errorf("OpenAPI definition without route: %s", endpoint)
+		}
+	}
+	slices.Sort(defined)
+	if duplicated := slices.Compact(slices.Clone(defined)); len(duplicated) != len(defined) {
+		t.Error("endpoints contains duplicated definitions")
+	}
+}
+
+// 定義の認証方式、scope、rate limitの有無が、登録したrouteのmiddlewareと一致する
+func TestEndpointsMatchMiddleware(t *testing.T) {
+	engine := newTestEngine(t)
+
+	for _, e := range endpoints() {
+		t.Run(e.Method+" "+e.Path, func(t *testing.T) {
+			switch e.Auth {
+			case openapi.AuthNone:
+				rec := serve(engine, e, nil)
+				if code := errorCode(rec); code == "MISSING_API_KEY" || code == "MISSING_ACCESS_TOKEN" {
+					t.Errorf("defined without auth, but the route requires it (%s)", code)
+				}
+				assertRateLimited(t, rec, e.RateLimited)
+
+			case openapi.AuthBearer:
+				if rec := serve(engine, e, nil); errorCode(rec) != "MISSING_ACCESS_TOKEN" {
+					t.Errorf("defined with bearer auth, but got %d %q without a token", rec.Code, errorCode(rec))
+				}
+				rec := serve(engine, e, http.Header{"Authorization": {"Bearer test"}})
+				assertRateLimited(t, rec, e.RateLimited)
+
+			case openapi.AuthAPIKey:
+				if rec := serve(engine, e, nil); errorCode(rec) != "MISSING_API_KEY" {
+					t.Errorf("defined with API key auth, but got %d %q without a key", rec.Code, errorCode(rec))
+				}
+				rec := serve(engine, e, http.Header{"X-API-Key": {"none"}})
+				if insufficient := errorCode(rec) == "INSUFFICIENT_SCOPE"; insufficient != (e.Scope != "") {
+					t.Errorf("defined scope %q, but a key without scopes got %d %q", e.Scope, rec.Code, errorCode(rec))
+				}
+				if e.Scope != "" {
+					rec = serve(engine, e, http.Header{"X-API-Key": {e.Scope}})
+					if errorCode(rec) == "INSUFFICIENT_SCOPE" {
+						t.Errorf("a key with the defined scope %q was rejected", e.Scope)
+					}
+				}
+				assertRateLimited(t, rec, e.RateLimited)
+			}
+		})
+	}
+}
+
+// serve pathのパラメータをUUIDに置き換えてリクエストする
+func serve(engine *gin.Engine, e openapi.Endpoint, header http.Header) *httptest.ResponseRecorder {
+	segments := strings.Split(e.Path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = uuid.NewString()
+		}
+	}
+	req := httptest.NewRequest(e.Method, strings.Join(segments, "/"), nil)
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorCode(rec *httptest.ResponseRecorder) string {
+	var body struct {
+		Code string `json:"code"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &body)
+	return body.Code
+}
+
+func assertRateLimited(t *testing.T, rec *httptest.ResponseRecorder, want bool) {
+	t.Helper()
+	if got := rec.Header().Get("RateLimit-Policy") != ""; got != want {
+		t.Errorf("rate limited = %v, want %v", got, want)
+	}
+}
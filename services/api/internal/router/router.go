@@ -1,14 +1,23 @@
 package router
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
 	"github.com/tokane888/test-mcp/services/api/internal/handler"
+	"github.com/tokane888/test-mcp/services/api/internal/openapi"
+	"github.com/tokane888/test-mcp/services/api/internal/ratelimit"
 	"github.com/tokane888/test-mcp/services/api/internal/router/middleware"
 	"go.uber.org/zap"
 )
 
 type Config struct {
 	Port int
+	// X-Forwarded-For等からクライアントIPを取得する際に信頼するproxyのIP、またはCIDR。
+	// nilの場合はどのproxyも信頼せず、接続元のIPをクライアントIPとする
+	TrustedProxies []string
+	RateLimit      ratelimit.Config
 }
 
 type Router struct {
@@ -16,39 +25,172 @@ type Router struct {
 	logger  *zap.Logger
 	engine  *gin.Engine
 	handler *handler.Handler
+	metrics middleware.HTTPMetrics
+	limiter ratelimit.Store
+	apiKeys middleware.APIKeyAuthenticator
+	tokens  middleware.AccessTokenVerifier
 }
 
-func NewRouter(config *Config, logger *zap.Logger, handler *handler.Handler) *Router {
+func NewRouter(
+	config *Config,
+	logger *zap.Logger,
+	handler *handler.Handler,
+	metrics middleware.HTTPMetrics,
+	limiter ratelimit.Store,
+	apiKeys middleware.APIKeyAuthenticator,
+	tokens middleware.AccessTokenVerifier,
+) *Router {
 	return &Router{
 		config:  config,
 		logger:  logger,
 		engine:  gin.New(),
 		handler: handler,
+		metrics: metrics,
+		limiter: limiter,
+		apiKeys: apiKeys,
+		tokens:  tokens,
 	}
 }
 
-func (r *Router) Setup() *gin.Engine {
+// Setup routeを登録する。routeを追加・変更した場合はOpenAPIドキュメントの定義(endpoints)も更新すること
+func (r *Router) Setup() (*gin.Engine, error) {
+	if err := handler.ConfigureBinding(); err != nil {
+		return nil, err
+	}
+	// rate limit、audit等で使用するクライアントIPを、信頼しないproxy経由のheaderで偽装させない
+	if err := r.engine.SetTrustedProxies(r.config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
 	// グローバルミドルウェア
+	// panic時の500も記録するためRecoveryより外側に置く
+	r.engine.Use(middleware.Metrics(r.metrics))
+	// 以降のmiddleware, handlerのログにtrace_idを出力するためRequestIDより外側に置く
+	r.engine.Use(middleware.Tracing())
+	r.engine.Use(middleware.RequestID(r.logger))
 	r.engine.Use(gin.Recovery()) // handler内でpanic発生時に500を返す
-	r.engine.Use(middleware.Logger(r.logger))
+	r.engine.Use(middleware.Logger())
 
 	// ヘルスチェック（認証不要）
 	r.engine.GET("/health", r.handler.Health)
+	r.engine.GET("/livez", r.handler.Livez)
+	r.engine.GET("/readyz", r.handler.Readyz)
+	// アクセストークンの検証用の公開鍵（認証不要）
+	r.engine.GET("/.well-known/jwks.json", r.handler.JWKS)
+
+	// エンドユーザー向けの認証エンドポイント（API Key不要）
+	authGroup := r.engine.Group("/api/v1/auth")
+	{
+		authGroup.Use(r.rateLimit("default", r.config.RateLimit.Default))
+		// パスワードの総当たりを防ぐためパスワード変更と同じ制限を適用する
+		authGroup.POST("/login", r.rateLimit("login", r.config.RateLimit.Password), r.handler.Login)
+		authGroup.POST("/refresh", r.handler.RefreshToken)
+		authGroup.POST("/logout", r.handler.Logout)
+	}
+
+	// エンドユーザー向けAPIグループ（アクセストークン認証）
+	me := r.engine.Group("/api/v1/me")
+	{
+		me.Use(middleware.JWTAuth(r.tokens))
+		me.Use(r.rateLimit("default", r.config.RateLimit.Default))
+		me.GET("", r.handler.GetMe)
+		me.PATCH("", r.handler.UpdateMe)
+		me.PUT("/password", r.rateLimit("password", r.config.RateLimit.Password), r.handler.ChangeMyPassword)
+	}
 
 	// APIグループ（v1）
 	v1 := r.engine.Group("/api/v1")
 	{
 		// API Key認証ミドルウェアを適用
-		v1.Use(middleware.APIKeyAuth())
+		v1.Use(middleware.APIKeyAuth(r.apiKeys))
+		// 無効なAPI Keyでbucketを作成させないよう認証後に制限する
+		v1.Use(r.rateLimit("default", r.config.RateLimit.Default))
 
 		// ユーザー管理エンドポイント
 		users := v1.Group("/users")
 		{
-			users.POST("", r.handler.CreateUser)
-			users.GET("", r.handler.ListUsers)
-			users.DELETE("/:id", r.handler.DeleteUser)
+			read := middleware.RequireScope(domain.ScopeUsersRead)
+			write := middleware.RequireScope(domain.ScopeUsersWrite)
+
+			users.POST("", write, r.rateLimit("create_user", r.config.RateLimit.CreateUser), r.handler.CreateUser)
+			users.GET("", read, r.handler.ListUsers)
+			users.GET("/by-email", read, r.handler.GetUserByEmail)
+			users.GET("/:id", read, r.handler.GetUser)
+			users.PATCH("/:id", write, r.handler.UpdateUser)
+			users.DELETE("/:id", write, r.handler.DeleteUser)
+			users.PUT("/:id/password", write, r.rateLimit("password", r.config.RateLimit.Password), r.handler.ChangePassword)
+			users.POST("/:id/restore", write, r.handler.RestoreUser)
+		}
+
+		// パスワードリセットエンドポイント
+		passwordReset := v1.Group("/password-reset")
+		{
+			passwordReset.Use(middleware.RequireScope(domain.ScopeUsersWrite))
+			passwordReset.Use(r.rateLimit("password", r.config.RateLimit.Password))
+			passwordReset.POST("", r.handler.RequestPasswordReset)
+			passwordReset.POST("/confirm", r.handler.ConfirmPasswordReset)
+		}
+
+		// 監査ログ。閲覧可否はroleに応じてusecase層で判定する
+		v1.GET("/audit", middleware.RequireScope(domain.ScopeUsersRead), r.handler.ListAuditEvents)
+
+		// webhookの購読管理。操作可否はroleに応じてusecase層で判定する
+		webhooks := v1.Group("/webhooks")
+		{
+			read := middleware.RequireScope(domain.ScopeUsersRead)
+			write := middleware.RequireScope(domain.ScopeUsersWrite)
+
+			webhooks.POST("", write, r.handler.CreateWebhook)
+			webhooks.GET("", read, r.handler.ListWebhooks)
+			webhooks.GET("/:id", read, r.handler.GetWebhook)
+			webhooks.PATCH("/:id", write, r.handler.UpdateWebhook)
+			webhooks.DELETE("/:id", write, r.handler.DeleteWebhook)
+			webhooks.GET("/:id/deliveries", read, r.handler.ListWebhookDeliveries)
+			webhooks.GET("/:id/deliveries/:delivery_id", read, r.handler.GetWebhookDelivery)
+			webhooks.POST("/:id/deliveries/:delivery_id/replay", write, r.handler.ReplayWebhookDelivery)
+		}
+	}
+
+	// 管理者向けAPIグループ（v1）
+	admin := r.engine.Group("/api/v1/admin")
+	{
+		// admin scopeを持つAPI Keyのみ許可する
+		admin.Use(middleware.APIKeyAuth(r.apiKeys))
+		admin.Use(middleware.RequireScope(domain.ScopeAdmin))
+		admin.Use(r.rateLimit("admin", r.config.RateLimit.Default))
+
+		admin.DELETE("/users/:id", r.handler.HardDeleteUser)
+		admin.PUT("/users/:id/role", r.handler.ChangeUserRole)
+
+		// API Key管理エンドポイント
+		apiKeys := admin.Group("/api-keys")
+		{
+			apiKeys.POST("", r.handler.IssueAPIKey)
+			apiKeys.GET("", r.handler.ListAPIKeys)
+			apiKeys.DELETE("/:id", r.handler.RevokeAPIKey)
+			apiKeys.POST("/:id/rotate", r.handler.RotateAPIKey)
 		}
 	}
 
-	return r.engine
+	// APIドキュメント（認証不要）
+	doc, err := OpenAPIDocument()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate OpenAPI document: %w", err)
+	}
+	specHandler, err := openapi.SpecHandler(doc)
+	if err != nil {
+		return nil, err
+	}
+	r.engine.GET(openAPIPath, specHandler)
+	r.engine.GET(docsPath, openapi.DocsHandler)
+
+	return r.engine, nil
+}
+
+// rateLimit 無効化されている場合は何もしないmiddlewareを返す
+func (r *Router) rateLimit(name string, limit ratelimit.Limit) gin.HandlerFunc {
+	if !r.config.RateLimit.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return middleware.RateLimit(r.limiter, name, limit)
 }
@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/policy"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// last_used_atの更新間隔。リクエストごとにDBへ書き込まないよう間引く
+const apiKeyLastUsedUpdateInterval = time.Minute
+
+// 初期管理者用キーの登録名
+const bootstrapAPIKeyName = "bootstrap-admin"
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrInvalidAPIKey 存在しない、失効済み、期限切れのいずれか。理由はクライアントに区別させない
+	ErrInvalidAPIKey        = errors.New("invalid api key")
+	ErrAPIKeyAlreadyRevoked = errors.New("api key is already revoked")
+	ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+	// ErrAPIKeyExpired 期限切れのキーはrotateできない。新たに発行する
+	ErrAPIKeyExpired = errors.New("api key has expired")
+)
+
+type APIKeyUseCase interface {
+	// IssueAPIKey 平文のキーを発行する。平文を取得できるのはこの時のみ。
+	// 操作主体より上位のroleのキーは発行できない
+	IssueAPIKey(ctx context.Context, req *request.IssueAPIKey) (*domain.APIKey, string, error)
+	ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	// RotateAPIKey 同じ名前・所有者・scope・有効期限の新しいキーを発行し、旧キーを猶予期間後に失効させる。
+	// 期限切れのキー、操作主体より上位のroleのキーはrotateできない
+	RotateAPIKey(ctx context.Context, id uuid.UUID, req *request.RotateAPIKey) (*domain.APIKey, string, error)
+	// Authenticate 平文のキーを検証し、認証に使用できるキーを返す
+	Authenticate(ctx context.Context, plain string) (*domain.APIKey, error)
+	// RegisterBootstrapKey 初期管理者用のキーを未登録の場合のみadmin scopeで登録する
+	RegisterBootstrapKey(ctx context.Context, plain string) error
+}
+
+type apiKeyUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+	txManager  repository.TxManager
+	logger     *zap.Logger
+}
+
+func NewAPIKeyUseCase(apiKeyRepo repository.APIKeyRepository, txManager repository.TxManager, logger *zap.Logger) APIKeyUseCase {
+	return &tracedAPIKeyUseCase{next: &apiKeyUseCase{
+		apiKeyRepo: apiKeyRepo,
+		txManager:  txManager,
+		logger:     logger,
+	}}
+}
+
+func (uc *apiKeyUseCase) IssueAPIKey(ctx context.Context, req *request.IssueAPIKey) (*domain.APIKey, string, error) {
+	scopes, err := domain.ParseAPIKeyScopes(req.Scopes)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidAPIKeyRequest, err)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKeyRequest)
+	}
+	role := domain.DefaultAPIKeyRole(scopes)
+	if req.Role != "" {
+		role = domain.Role(req.Role)
+	}
+	if err := authorizeAPIKeyRole(ctx, role); err != nil {
+		return nil, "", err
+	}
+
+	key, plain, err := domain.NewAPIKey(req.Name, req.Owner, scopes, role, req.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAPIKeyRole) {
+			return nil, "", fmt.Errorf("%w: %w", ErrInvalidAPIKeyRequest, err)
+		}
+		return nil, "", fmt.Errorf("failed to create api key entity: %w", err)
+	}
+	if err := uc.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("failed to save api key: %w", err)
+	}
+
+	pkglogger.FromContext(ctx).Info("api key issued",
+		zap.String("api_key_id", key.ID().String()),
+		zap.String("name", key.Name()),
+		zap.String("owner", key.Owner()),
+		zap.String("role", string(key.Role())),
+	)
+	return key, plain, nil
+}
+
+func (uc *apiKeyUseCase) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	keys, err := uc.apiKeyRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (uc *apiKeyUseCase) RevokeAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	var key *domain.APIKey
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		key, err = uc.revokeAPIKey(ctx, id, time.Now())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pkglogger.FromContext(ctx).Info("api key revoked", zap.String("api_key_id", id.String()))
+	return key, nil
+}
+
+func (uc *apiKeyUseCase) revokeAPIKey(ctx context.Context, id uuid.UUID, at time.Time) (*domain.APIKey, error) {
+	key, err := uc.apiKeyRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to find api key: %w", err)
+	}
+
+	if err := key.Revoke(at); err != nil {
+		if errors.Is(err, domain.ErrAPIKeyRevoked) {
+			return nil, ErrAPIKeyAlreadyRevoked
+		}
+		return nil, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if err := uc.apiKeyRepo.UpdateRevokedAt(ctx, key); err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to update api key: %w", err)
+	}
+	return key, nil
+}
+
+func (uc *apiKeyUseCase) RotateAPIKey(ctx context.Context, id uuid.UUID, req *request.RotateAPIKey) (*domain.APIKey, string, error) {
+	var (
+		key   *domain.APIKey
+		plain string
+	)
+	// 新しいキーの発行と旧キーの失効を同一トランザクションで行い、片方のみ反映されることを防ぐ
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		old, err := uc.apiKeyRepo.FindByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrAPIKeyNotFound) {
+				return ErrAPIKeyNotFound
+			}
+			return fmt.Errorf("failed to find api key: %w", err)
+		}
+		if err := authorizeAPIKeyRole(ctx, old.Role()); err != nil {
+			return err
+		}
+		// 新しいキーは旧キーの有効期限を引き継ぐため、期限切れの場合は発行と同時に無効となる
+		if expiresAt := old.ExpiresAt(); expiresAt != nil && !now.Before(*expiresAt) {
+			return ErrAPIKeyExpired
+		}
+
+		old, err = uc.revokeAPIKey(ctx, id, now.Add(time.Duration(req.GracePeriodSeconds)*time.Second))
+		if err != nil {
+			return err
+		}
+
+		key, plain, err = domain.NewAPIKey(old.Name(), old.Owner(), old.Scopes(), old.Role(), old.ExpiresAt())
+		if err != nil {
+			return fmt.Errorf("failed to create api key entity: %w", err)
+		}
+		if err := uc.apiKeyRepo.Create(ctx, key); err != nil {
+			return fmt.Errorf("failed to save api key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	pkglogger.FromContext(ctx).Info("api key rotated",
+		zap.String("old_api_key_id", id.String()),
+		zap.String("api_key_id", key.ID().String()),
+		zap.Int("grace_period_seconds", req.GracePeriodSeconds),
+	)
+	return key, plain, nil
+}
+
+func (uc *apiKeyUseCase) Authenticate(ctx context.Context, plain string) (*domain.APIKey, error) {
+	key, err := uc.apiKeyRepo.FindByKeyHash(ctx, domain.HashAPIKey(plain))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to find api key: %w", err)
+	}
+	// ハッシュでの検索はDBのindexによるため、取得後に実行時間が一定となる比較で再確認する
+	if !key.Matches(plain) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if err := key.Validate(now); err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if last := key.LastUsedAt(); last == nil || now.Sub(*last) >= apiKeyLastUsedUpdateInterval {
+		// 記録に失敗しても認証自体は成功させる
+		if err := uc.apiKeyRepo.UpdateLastUsedAt(ctx, key.ID(), now); err != nil {
+			pkglogger.FromContext(ctx).Warn("failed to update api key last used at",
+				zap.String("api_key_id", key.ID().String()), zap.Error(err))
+		} else {
+			key.MarkUsed(now)
+		}
+	}
+	return key, nil
+}
+
+func (uc *apiKeyUseCase) RegisterBootstrapKey(ctx context.Context, plain string) error {
+	key, err := domain.NewAPIKeyFromPlain(bootstrapAPIKeyName, "system", plain, []domain.APIKeyScope{domain.ScopeAdmin}, domain.RoleAdmin, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create api key entity: %w", err)
+	}
+	if err := uc.apiKeyRepo.Create(ctx, key); err != nil {
+		// 2回目以降の起動では登録済み。失効させた場合も再登録はしない
+		if errors.Is(err, repository.ErrAPIKeyAlreadyExists) {
+			return nil
+		}
+		return fmt.Errorf("failed to save bootstrap api key: %w", err)
+	}
+
+	pkglogger.FromContext(ctx).Info("bootstrap api key registered", zap.String("api_key_id", key.ID().String()))
+	return nil
+}
+
+// authorizeAPIKeyRole 操作主体のroleがroleを包含しない場合はErrForbiddenを返す。
+// admin scopeのみで判定すると、下位のroleのキーから上位のroleのキーを発行できてしまう
+func authorizeAPIKeyRole(ctx context.Context, role domain.Role) error {
+	principal, ok := policy.FromContext(ctx)
+	if !ok || !principal.Role.Covers(role) {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/infrastructure/memory"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"github.com/tokane888/test-mcp/services/api/internal/usecase"
+	"go.uber.org/zap"
+)
+
+// admin scopeを持つキーであっても、自身より上位のroleのキーは発行できない
+func TestIssueAPIKeyRoleCeiling(t *testing.T) {
+	tests := []struct {
+		name    string
+		actor   domain.Role
+		scopes  []string
+		role    string
+		wantErr error
+	}{
+		{"admin issues admin", domain.RoleAdmin, []string{"admin"}, "admin", nil},
+		{"operator issues operator", domain.RoleOperator, []string{"users:write"}, "operator", nil},
+		{"operator issues viewer", domain.RoleOperator, []string{"admin"}, "viewer", nil},
+		{"operator issues admin", domain.RoleOperator, []string{"admin"}, "admin", usecase.ErrForbidden},
+		{"viewer issues operator", domain.RoleViewer, []string{"users:write"}, "operator", usecase.ErrForbidden},
+		// role未指定の場合はscopeから決まるroleで判定する
+		{"operator issues default admin", domain.RoleOperator, []string{"admin"}, "", usecase.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := memory.NewAPIKeyRepository()
+			uc := newAPIKeyUseCase(keys)
+
+			req := &request.IssueAPIKey{Name: "key", Owner: "owner", Scopes: tt.scopes, Role: tt.role}
+			key, plain, err := uc.IssueAPIKey(asRole(tt.actor), req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("IssueAPIKey error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && (key != nil || plain != "") {
+				t.Error("api key issued despite the error")
+			}
+		})
+	}
+}
+
+func TestRotateAPIKeyRoleCeiling(t *testing.T) {
+	keys := memory.NewAPIKeyRepository()
+	uc := newAPIKeyUseCase(keys)
+	adminKey := createAPIKey(t, keys, domain.RoleAdmin, nil)
+
+	if _, _, err := uc.RotateAPIKey(asRole(domain.RoleOperator), adminKey.ID(), &request.RotateAPIKey{}); !errors.Is(err, usecase.ErrForbidden) {
+		t.Fatalf("RotateAPIKey error = %v, want %v", err, usecase.ErrForbidden)
+	}
+	// 拒否された場合は旧キーを失効させない
+	stored, err := keys.FindByID(context.Background(), adminKey.ID())
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if stored.RevokedAt() != nil {
+		t.Error("api key revoked despite the error")
+	}
+}
+
+func TestRotateExpiredAPIKey(t *testing.T) {
+	keys := memory.NewAPIKeyRepository()
+	uc := newAPIKeyUseCase(keys)
+	expiresAt := time.Now().Add(-time.Hour)
+	expired := createAPIKey(t, keys, domain.RoleViewer, &expiresAt)
+
+	if _, _, err := uc.RotateAPIKey(asRole(domain.RoleAdmin), expired.ID(), &request.RotateAPIKey{}); !errors.Is(err, usecase.ErrAPIKeyExpired) {
+		t.Fatalf("RotateAPIKey error = %v, want %v", err, usecase.ErrAPIKeyExpired)
+	}
+}
+
+func newAPIKeyUseCase(keys repository.APIKeyRepository) usecase.APIKeyUseCase {
+	return usecase.NewAPIKeyUseCase(keys, memory.NewTxManager(keys), zap.NewNop())
+}
+
+func createAPIKey(t *testing.T, keys repository.APIKeyRepository, role domain.Role, expiresAt *time.Time) *domain.APIKey {
+	t.Helper()
+	scopes := []domain.APIKeyScope{domain.ScopeAdmin}
+	key := domain.ReconstructAPIKey(uuid.New(), "key", "owner", "prefix", uuid.NewString(), scopes, role, expiresAt, nil, nil, time.Now())
+	if err := keys.Create(context.Background(), key); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return key
+}
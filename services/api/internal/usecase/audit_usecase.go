@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/query"
+	"github.com/tokane888/test-mcp/services/api/internal/policy"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"github.com/tokane888/test-mcp/services/api/internal/requestmeta"
+	"go.uber.org/zap"
+)
+
+type AuditUseCase interface {
+	ListAuditEvents(ctx context.Context, q *query.ListAuditEvents) (*AuditEventList, error)
+}
+
+type AuditEventList struct {
+	Events []*domain.AuditEvent
+	// 次のページが存在しない場合は空文字
+	NextCursor string
+}
+
+type auditUseCase struct {
+	auditRepo  repository.AuditEventRepository
+	authorizer Authorizer
+	logger     *zap.Logger
+}
+
+func NewAuditUseCase(auditRepo repository.AuditEventRepository, authorizer Authorizer, logger *zap.Logger) AuditUseCase {
+	return &tracedAuditUseCase{next: &auditUseCase{
+		auditRepo:  auditRepo,
+		authorizer: authorizer,
+		logger:     logger,
+	}}
+}
+
+func (uc *auditUseCase) ListAuditEvents(ctx context.Context, q *query.ListAuditEvents) (*AuditEventList, error) {
+	if err := authorize(ctx, uc.authorizer, policy.ActionAuditRead, nil); err != nil {
+		return nil, err
+	}
+
+	params := repository.AuditEventListParams{
+		Filter: repository.AuditEventFilter{
+			ActorType: domain.AuditActorType(q.ActorType),
+			ActorID:   q.ActorID,
+			Action:    domain.AuditAction(q.Action),
+			From:      q.From,
+			To:        q.To,
+		},
+		Limit: q.Limit,
+	}
+	if q.TargetUserID != "" {
+		// 形式はリクエストのバインド時に検証済み
+		id, err := uuid.Parse(q.TargetUserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse target user id: %w", err)
+		}
+		params.Filter.TargetUserID = id
+	}
+	if q.Cursor != "" {
+		after, err := decodeAuditCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		params.After = after
+	}
+
+	result, err := uc.auditRepo.List(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return &AuditEventList{
+		Events:     result.Events,
+		NextCursor: encodeAuditCursor(result.NextCursor),
+	}, nil
+}
+
+// recordAuditEvent 対象の操作と同じトランザクション内で呼び出す。
+// 操作主体・リクエストIDはcontextから取得する
+func recordAuditEvent(
+	ctx context.Context,
+	auditRepo repository.AuditEventRepository,
+	action domain.AuditAction,
+	targetUserID uuid.UUID,
+	before map[string]any,
+	after map[string]any,
+) error {
+	return recordAuditEventAs(ctx, auditRepo, auditActorFromContext(ctx), action, targetUserID, before, after)
+}
+
+// recordAuditEventAs パスワードリセット等、認証を経ずに操作主体が定まる場合に使用する
+func recordAuditEventAs(
+	ctx context.Context,
+	auditRepo repository.AuditEventRepository,
+	actor domain.AuditActor,
+	action domain.AuditAction,
+	targetUserID uuid.UUID,
+	before map[string]any,
+	after map[string]any,
+) error {
+	meta := requestmeta.FromContext(ctx)
+	event := domain.NewAuditEvent(actor, action, targetUserID, before, after, meta.RequestID, meta.ClientIP)
+	if err := auditRepo.Create(ctx, event); err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+func auditActorFromContext(ctx context.Context) domain.AuditActor {
+	p, ok := policy.FromContext(ctx)
+	switch {
+	case ok && p.APIKeyID != uuid.Nil:
+		return domain.AuditActor{Type: domain.AuditActorAPIKey, ID: p.APIKeyID.String()}
+	case ok && p.UserID != uuid.Nil:
+		return domain.AuditActor{Type: domain.AuditActorUser, ID: p.UserID.String()}
+	default:
+		return domain.AuditActor{Type: domain.AuditActorSystem}
+	}
+}
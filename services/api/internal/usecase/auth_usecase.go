@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidCredentials ユーザーの存在を推測させないよう、メールアドレスとパスワードのどちらが誤りかは区別しない
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused 使用済みのリフレッシュトークンが再度使用された。系列全体を失効させた
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+// TokenIssuer auth.Managerが満たす
+type TokenIssuer interface {
+	IssueAccessToken(userID uuid.UUID, role domain.Role) (string, time.Time, error)
+}
+
+type TokenPair struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+type AuthUseCase interface {
+	Login(ctx context.Context, req *request.Login) (*TokenPair, error)
+	// Refresh リフレッシュトークンを使用済みにし、同じ系列の新しいトークンと共にアクセストークンを再発行する
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	// Logout リフレッシュトークンの系列を失効させる。未知のトークンの場合も成功とする
+	Logout(ctx context.Context, refreshToken string) error
+}
+
+type authUseCase struct {
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	txManager        repository.TxManager
+	tokens           TokenIssuer
+	refreshTokenTTL  time.Duration
+	logger           *zap.Logger
+}
+
+func NewAuthUseCase(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	txManager repository.TxManager,
+	tokens TokenIssuer,
+	refreshTokenTTL time.Duration,
+	logger *zap.Logger,
+) AuthUseCase {
+	return &tracedAuthUseCase{next: &authUseCase{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		txManager:        txManager,
+		tokens:           tokens,
+		refreshTokenTTL:  refreshTokenTTL,
+		logger:           logger,
+	}}
+}
+
+func (uc *authUseCase) Login(ctx context.Context, req *request.Login) (*TokenPair, error) {
+	user, err := uc.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			domain.VerifyDummyPassword(req.Password)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to find user by email: %w", err)
+	}
+	if !user.VerifyPassword(req.Password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	// ログインごとに新しい系列を開始する
+	pair, err := uc.issueTokenPair(ctx, user, uuid.New())
+	if err != nil {
+		return nil, err
+	}
+	pkglogger.FromContext(ctx).Info("user logged in", zap.String("user_id", user.ID().String()))
+	return pair, nil
+}
+
+func (uc *authUseCase) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	var (
+		pair       *TokenPair
+		reused     *domain.RefreshToken
+		refreshErr error
+	)
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		pair, reused, refreshErr = uc.refresh(ctx, refreshToken)
+		return refreshErr
+	})
+	if reused != nil {
+		// rollbackされないよう、系列の失効はトランザクションの外で行う
+		if err := uc.refreshTokenRepo.RevokeFamily(ctx, reused.FamilyID(), time.Now()); err != nil {
+			return nil, fmt.Errorf("failed to revoke refresh token family: %w", err)
+		}
+		pkglogger.FromContext(ctx).Warn("refresh token reuse detected; revoked token family",
+			zap.String("user_id", reused.UserID().String()),
+			zap.String("family_id", reused.FamilyID().String()),
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// refresh 再利用を検知した場合は、呼び出し元で系列を失効させるためトークンも返す
+func (uc *authUseCase) refresh(ctx context.Context, refreshToken string) (*TokenPair, *domain.RefreshToken, error) {
+	token, err := uc.refreshTokenRepo.FindByTokenHash(ctx, domain.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+
+	if err := token.Use(); err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			return nil, token, ErrRefreshTokenReused
+		}
+		if errors.Is(err, domain.ErrRefreshTokenRevoked) || errors.Is(err, domain.ErrRefreshTokenExpired) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, fmt.Errorf("failed to use refresh token: %w", err)
+	}
+	if err := uc.refreshTokenRepo.MarkUsed(ctx, token); err != nil {
+		// FindByTokenHashの後に並行したリクエストが使用した場合も再利用とみなす
+		if errors.Is(err, repository.ErrRefreshTokenAlreadyUsed) {
+			return nil, token, ErrRefreshTokenReused
+		}
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, fmt.Errorf("failed to mark refresh token as used: %w", err)
+	}
+
+	// 削除済みのユーザーには発行しない。roleの変更はここで反映される
+	user, err := uc.userRepo.FindByID(ctx, token.UserID())
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	pair, err := uc.issueTokenPair(ctx, user, token.FamilyID())
+	if err != nil {
+		return nil, nil, err
+	}
+	return pair, nil, nil
+}
+
+func (uc *authUseCase) Logout(ctx context.Context, refreshToken string) error {
+	token, err := uc.refreshTokenRepo.FindByTokenHash(ctx, domain.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to find refresh token: %w", err)
+	}
+
+	if err := uc.refreshTokenRepo.RevokeFamily(ctx, token.FamilyID(), time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	pkglogger.FromContext(ctx).Info("user logged out", zap.String("user_id", token.UserID().String()))
+	return nil
+}
+
+func (uc *authUseCase) issueTokenPair(ctx context.Context, user *domain.User, familyID uuid.UUID) (*TokenPair, error) {
+	refreshToken, plain, err := domain.NewRefreshToken(user.ID(), familyID, uc.refreshTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	if err := uc.refreshTokenRepo.Create(ctx, refreshToken); err != nil {
+		return nil, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	accessToken, expiresAt, err := uc.tokens.IssueAccessToken(user.ID(), user.Role())
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue access token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  expiresAt,
+		RefreshToken:          plain,
+		RefreshTokenExpiresAt: refreshToken.ExpiresAt(),
+	}, nil
+}
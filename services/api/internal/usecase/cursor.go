@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// userCursorPayload カーソルの内部表現。ソート条件を含め、異なるソート条件での再利用を検出する
+type userCursorPayload struct {
+	Sort  repository.UserSortField `json:"s"`
+	Desc  bool                     `json:"d"`
+	Value string                   `json:"v"`
+	ID    uuid.UUID                `json:"id"`
+}
+
+// encodeUserCursor カーソルをクライアントにとって不透明な文字列に変換
+func encodeUserCursor(cursor *repository.UserCursor, sort repository.UserSort) string {
+	if cursor == nil {
+		return ""
+	}
+	payload := userCursorPayload{
+		Sort: sort.Field,
+		Desc: sort.Desc,
+		ID:   cursor.ID,
+	}
+	switch v := cursor.Value.(type) {
+	case time.Time:
+		payload.Value = v.UTC().Format(time.RFC3339Nano)
+	case string:
+		payload.Value = v
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeUserCursor(s string, sort repository.UserSort) (*repository.UserCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var payload userCursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if payload.Sort != sort.Field || payload.Desc != sort.Desc {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &repository.UserCursor{ID: payload.ID}
+	switch sort.Field {
+	case repository.UserSortByCreatedAt, repository.UserSortByUpdatedAt:
+		t, err := time.Parse(time.RFC3339Nano, payload.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		cursor.Value = t
+	default:
+		cursor.Value = payload.Value
+	}
+	return cursor, nil
+}
+
+// timeCursorPayload 日時の降順、同時刻はIDの降順で並べる一覧のカーソル
+type timeCursorPayload struct {
+	Time time.Time `json:"t"`
+	ID   uuid.UUID `json:"id"`
+}
+
+func encodeTimeCursor(t time.Time, id uuid.UUID) string {
+	b, err := json.Marshal(timeCursorPayload{Time: t.UTC(), ID: id})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeTimeCursor(s string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	var payload timeCursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	if payload.Time.IsZero() || payload.ID == uuid.Nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+	return payload.Time, payload.ID, nil
+}
+
+func encodeAuditCursor(cursor *repository.AuditEventCursor) string {
+	if cursor == nil {
+		return ""
+	}
+	return encodeTimeCursor(cursor.OccurredAt, cursor.ID)
+}
+
+func decodeAuditCursor(s string) (*repository.AuditEventCursor, error) {
+	t, id, err := decodeTimeCursor(s)
+	if err != nil {
+		return nil, err
+	}
+	return &repository.AuditEventCursor{OccurredAt: t, ID: id}, nil
+}
+
+func encodeWebhookDeliveryCursor(cursor *repository.WebhookDeliveryCursor) string {
+	if cursor == nil {
+		return ""
+	}
+	return encodeTimeCursor(cursor.CreatedAt, cursor.ID)
+}
+
+func decodeWebhookDeliveryCursor(s string) (*repository.WebhookDeliveryCursor, error) {
+	t, id, err := decodeTimeCursor(s)
+	if err != nil {
+		return nil, err
+	}
+	return &repository.WebhookDeliveryCursor{CreatedAt: t, ID: id}, nil
+}
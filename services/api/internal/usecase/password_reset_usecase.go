@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/policy"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// リセットトークンの有効期間
+const passwordResetTokenTTL = 30 * time.Minute
+
+var ErrInvalidResetToken = errors.New("invalid password reset token")
+
+type PasswordResetUseCase interface {
+	// RequestPasswordReset 平文のリセットトークンを発行する。呼び出し元がメール等でユーザーへ届ける
+	RequestPasswordReset(ctx context.Context, req *request.RequestPasswordReset) (string, time.Time, error)
+	ConfirmPasswordReset(ctx context.Context, req *request.ConfirmPasswordReset) error
+}
+
+type passwordResetUseCase struct {
+	userRepo         repository.UserRepository
+	tokenRepo        repository.PasswordResetTokenRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	auditRepo        repository.AuditEventRepository
+	txManager        repository.TxManager
+	authorizer       Authorizer
+	logger           *zap.Logger
+}
+
+func NewPasswordResetUseCase(
+	userRepo repository.UserRepository,
+	tokenRepo repository.PasswordResetTokenRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	auditRepo repository.AuditEventRepository,
+	txManager repository.TxManager,
+	authorizer Authorizer,
+	logger *zap.Logger,
+) PasswordResetUseCase {
+	return &tracedPasswordResetUseCase{next: &passwordResetUseCase{
+		userRepo:         userRepo,
+		tokenRepo:        tokenRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		auditRepo:        auditRepo,
+		txManager:        txManager,
+		authorizer:       authorizer,
+		logger:           logger,
+	}}
+}
+
+func (uc *passwordResetUseCase) RequestPasswordReset(ctx context.Context, req *request.RequestPasswordReset) (string, time.Time, error) {
+	// メールアドレスで検索する権限が無い操作主体には、存在しないユーザーも権限が無い場合と同じエラーとし、
+	// 応答の違いからメールアドレスの存在を確認させない
+	canLookup := true
+	if err := authorize(ctx, uc.authorizer, policy.ActionUserList, nil); err != nil {
+		if !errors.Is(err, ErrForbidden) {
+			return "", time.Time{}, err
+		}
+		canLookup = false
+	}
+
+	user, err := uc.userRepo.FindByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			if !canLookup {
+				return "", time.Time{}, ErrForbidden
+			}
+			return "", time.Time{}, ErrUserNotFound
+		}
+		return "", time.Time{}, fmt.Errorf("failed to find user by email: %w", err)
+	}
+	// トークンを所持すればパスワードを変更できるため、パスワード変更と同じ権限を要求する
+	if err := authorize(ctx, uc.authorizer, policy.ActionUserChangePassword, user); err != nil {
+		return "", time.Time{}, err
+	}
+
+	token, plain, err := domain.NewPasswordResetToken(user.ID(), passwordResetTokenTTL)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	if err := uc.tokenRepo.Create(ctx, token); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to save password reset token: %w", err)
+	}
+	pkglogger.FromContext(ctx).Info("password reset token issued",
+		zap.String("user_id", user.ID().String()),
+		zap.Time("expires_at", token.ExpiresAt()),
+	)
+
+	return plain, token.ExpiresAt(), nil
+}
+
+func (uc *passwordResetUseCase) ConfirmPasswordReset(ctx context.Context, req *request.ConfirmPasswordReset) error {
+	// トークンの消費とパスワードの更新を同一トランザクションで行い、片方のみ反映されることを防ぐ
+	var userID uuid.UUID
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		userID, err = uc.confirmPasswordReset(ctx, req)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	pkglogger.FromContext(ctx).Info("password reset completed", zap.String("user_id", userID.String()))
+	return nil
+}
+
+func (uc *passwordResetUseCase) confirmPasswordReset(ctx context.Context, req *request.ConfirmPasswordReset) (uuid.UUID, error) {
+	token, err := uc.tokenRepo.FindByTokenHash(ctx, domain.HashResetToken(req.Token))
+	if err != nil {
+		if errors.Is(err, repository.ErrResetTokenNotFound) {
+			return uuid.Nil, ErrInvalidResetToken
+		}
+		return uuid.Nil, fmt.Errorf("failed to find password reset token: %w", err)
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, token.UserID())
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return uuid.Nil, ErrInvalidResetToken
+		}
+		return uuid.Nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	// 発行後にroleが変更された場合に備え、確定時にも権限を確認する
+	if err := authorize(ctx, uc.authorizer, policy.ActionUserChangePassword, user); err != nil {
+		return uuid.Nil, err
+	}
+	before := domain.UserAuditFields(user)
+
+	// トークンを消費する前にパスワードを検証し、形式不正でトークンが無駄にならないようにする
+	if err := user.ResetPassword(req.NewPassword); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to reset password: %w", err)
+	}
+
+	if err := token.Use(); err != nil {
+		if errors.Is(err, domain.ErrResetTokenUsed) || errors.Is(err, domain.ErrResetTokenExpired) {
+			return uuid.Nil, ErrInvalidResetToken
+		}
+		return uuid.Nil, fmt.Errorf("failed to use password reset token: %w", err)
+	}
+	if err := uc.tokenRepo.MarkUsed(ctx, token); err != nil {
+		if errors.Is(err, repository.ErrResetTokenNotFound) {
+			return uuid.Nil, ErrInvalidResetToken
+		}
+		return uuid.Nil, fmt.Errorf("failed to mark password reset token as used: %w", err)
+	}
+
+	if err := uc.userRepo.UpdatePassword(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return uuid.Nil, ErrInvalidResetToken
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return uuid.Nil, ErrConflict
+		}
+		return uuid.Nil, fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// パスワードの漏洩を疑ってリセットした場合に備え、既存のセッションを失効させる
+	if err := uc.refreshTokenRepo.RevokeAllForUser(ctx, user.ID(), time.Now()); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	// リセットトークンの所持によって本人と確認できたため、対象ユーザー自身を操作主体とする
+	actor := domain.AuditActor{Type: domain.AuditActorUser, ID: user.ID().String()}
+	if err := recordAuditEventAs(ctx, uc.auditRepo, actor, domain.AuditActionUserPasswordReset, user.ID(), before, domain.UserAuditFields(user)); err != nil {
+		return uuid.Nil, err
+	}
+
+	return user.ID(), nil
+}
@@ -0,0 +1,121 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/infrastructure/memory"
+	"github.com/tokane888/test-mcp/services/api/internal/policy"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"github.com/tokane888/test-mcp/services/api/internal/usecase"
+	"go.uber.org/zap"
+)
+
+func TestRequestPasswordResetAuthorization(t *testing.T) {
+	tests := []struct {
+		name       string
+		actor      domain.Role
+		targetRole domain.Role
+		wantErr    error
+	}{
+		{"admin resets admin", domain.RoleAdmin, domain.RoleAdmin, nil},
+		{"operator resets non-admin", domain.RoleOperator, domain.RoleSelf, nil},
+		{"operator resets admin", domain.RoleOperator, domain.RoleAdmin, usecase.ErrForbidden},
+		{"viewer resets non-admin", domain.RoleViewer, domain.RoleSelf, usecase.ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := memory.NewUserRepository()
+			uc := newPasswordResetUseCase(users)
+			target := createUser(t, users, "target@example.com", tt.targetRole)
+
+			token, _, err := uc.RequestPasswordReset(asRole(tt.actor), &request.RequestPasswordReset{Email: target.Email()})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RequestPasswordReset error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && token != "" {
+				t.Error("token issued despite the error")
+			}
+		})
+	}
+}
+
+// 検索の権限が無い操作主体には、ユーザーの有無を応答から区別させない
+func TestRequestPasswordResetDoesNotRevealEmails(t *testing.T) {
+	users := memory.NewUserRepository()
+	uc := newPasswordResetUseCase(users)
+	createUser(t, users, "existing@example.com", domain.RoleSelf)
+	self := policy.NewContext(context.Background(), &policy.Principal{Role: domain.RoleSelf, UserID: uuid.New()})
+
+	for _, email := range []string{"existing@example.com", "unknown@example.com"} {
+		if _, _, err := uc.RequestPasswordReset(self, &request.RequestPasswordReset{Email: email}); !errors.Is(err, usecase.ErrForbidden) {
+			t.Errorf("RequestPasswordReset(%s) error = %v, want %v", email, err, usecase.ErrForbidden)
+		}
+	}
+	// 検索できる操作主体には従来どおり存在しないことを返す
+	_, _, err := uc.RequestPasswordReset(asRole(domain.RoleOperator), &request.RequestPasswordReset{Email: "unknown@example.com"})
+	if !errors.Is(err, usecase.ErrUserNotFound) {
+		t.Errorf("RequestPasswordReset by operator error = %v, want %v", err, usecase.ErrUserNotFound)
+	}
+}
+
+// 発行後に対象ユーザーが管理者となった場合、権限の無い操作主体は確定できない
+func TestConfirmPasswordResetAuthorization(t *testing.T) {
+	users := memory.NewUserRepository()
+	uc := newPasswordResetUseCase(users)
+	target := createUser(t, users, "target@example.com", domain.RoleSelf)
+
+	token, _, err := uc.RequestPasswordReset(asRole(domain.RoleOperator), &request.RequestPasswordReset{Email: target.Email()})
+	if err != nil {
+		t.Fatalf("RequestPasswordReset: %v", err)
+	}
+	if err := target.ChangeRole(domain.RoleAdmin); err != nil {
+		t.Fatalf("ChangeRole: %v", err)
+	}
+	if err := users.Update(context.Background(), target); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	req := &request.ConfirmPasswordReset{Token: token, NewPassword: "new-password1"}
+	if err := uc.ConfirmPasswordReset(asRole(domain.RoleOperator), req); !errors.Is(err, usecase.ErrForbidden) {
+		t.Fatalf("ConfirmPasswordReset error = %v, want %v", err, usecase.ErrForbidden)
+	}
+	// 拒否された場合はトークンを消費しない
+	if err := uc.ConfirmPasswordReset(asRole(domain.RoleAdmin), req); err != nil {
+		t.Fatalf("ConfirmPasswordReset by admin: %v", err)
+	}
+}
+
+func newPasswordResetUseCase(users repository.UserRepository) usecase.PasswordResetUseCase {
+	tokens := memory.NewPasswordResetTokenRepository()
+	refreshTokens := memory.NewRefreshTokenRepository()
+	audits := memory.NewAuditEventRepository()
+	return usecase.NewPasswordResetUseCase(
+		users,
+		tokens,
+		refreshTokens,
+		audits,
+		memory.NewTxManager(users, tokens, refreshTokens, audits),
+		policy.NewPolicy(),
+		zap.NewNop(),
+	)
+}
+
+func createUser(t *testing.T, users repository.UserRepository, email string, role domain.Role) *domain.User {
+	t.Helper()
+	now := time.Now()
+	user := domain.ReconstructUser(uuid.New(), email, "target", "dummy-hash", role, now, now, nil, 1)
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return user
+}
+
+func asRole(role domain.Role) context.Context {
+	return policy.NewContext(context.Background(), &policy.Principal{Role: role, APIKeyID: uuid.New()})
+}
@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/query"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/tokane888/test-mcp/services/api/internal/usecase")
+
+// tracedUserUseCase UserUseCaseの各メソッドの呼び出しをspanとして記録する
+type tracedUserUseCase struct {
+	next UserUseCase
+}
+
+func (t *tracedUserUseCase) CreateUser(ctx context.Context, req *request.CreateUser) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.CreateUser")
+	user, err := t.next.CreateUser(ctx, req)
+	if err == nil {
+		span.SetAttributes(userIDAttribute(user.ID()))
+	}
+	tracing.EndSpan(span, err)
+	return user, err
+}
+
+func (t *tracedUserUseCase) GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.GetUser", trace.WithAttributes(userIDAttribute(id)))
+	user, err := t.next.GetUser(ctx, id)
+	tracing.EndSpan(span, err)
+	return user, err
+}
+
+func (t *tracedUserUseCase) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	// メールアドレスは個人情報のため記録しない
+	ctx, span := tracer.Start(ctx, "UserUseCase.GetUserByEmail")
+	user, err := t.next.GetUserByEmail(ctx, email)
+	tracing.EndSpan(span, err)
+	return user, err
+}
+
+func (t *tracedUserUseCase) ListUsers(ctx context.Context, q *query.ListUsers) (*UserList, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.ListUsers", trace.WithAttributes(
+		attribute.Int("users.limit", q.Limit),
+		attribute.String("users.sort", q.Sort),
+		attribute.Bool("users.cursor", q.Cursor != ""),
+	))
+	list, err := t.next.ListUsers(ctx, q)
+	if err == nil {
+		span.SetAttributes(attribute.Int("users.count", len(list.Users)))
+	}
+	tracing.EndSpan(span, err)
+	return list, err
+}
+
+func (t *tracedUserUseCase) UpdateUser(ctx context.Context, id uuid.UUID, expectedVersions []int, req *request.UpdateUser) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.UpdateUser", trace.WithAttributes(userIDAttribute(id)))
+	user, err := t.next.UpdateUser(ctx, id, expectedVersions, req)
+	tracing.EndSpan(span, err)
+	return user, err
+}
+
+func (t *tracedUserUseCase) ChangePassword(ctx context.Context, id uuid.UUID, req *request.ChangePassword) error {
+	ctx, span := tracer.Start(ctx, "UserUseCase.ChangePassword", trace.WithAttributes(userIDAttribute(id)))
+	err := t.next.ChangePassword(ctx, id, req)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (t *tracedUserUseCase) DeleteUser(ctx context.Context, id uuid.UUID, expectedVersions []int) error {
+	ctx, span := tracer.Start(ctx, "UserUseCase.DeleteUser", trace.WithAttributes(userIDAttribute(id)))
+	err := t.next.DeleteUser(ctx, id, expectedVersions)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (t *tracedUserUseCase) RestoreUser(ctx context.Context, id uuid.UUID, expectedVersions []int) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.RestoreUser", trace.WithAttributes(userIDAttribute(id)))
+	user, err := t.next.RestoreUser(ctx, id, expectedVersions)
+	tracing.EndSpan(span, err)
+	return user, err
+}
+
+func (t *tracedUserUseCase) HardDeleteUser(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "UserUseCase.HardDeleteUser", trace.WithAttributes(userIDAttribute(id)))
+	err := t.next.HardDeleteUser(ctx, id)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (t *tracedUserUseCase) ChangeRole(ctx context.Context, id uuid.UUID, expectedVersions []int, role domain.Role) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUseCase.ChangeRole", trace.WithAttributes(
+		userIDAttribute(id),
+		attribute.String("user.role", string(role)),
+	))
+	user, err := t.next.ChangeRole(ctx, id, expectedVersions, role)
+	tracing.EndSpan(span, err)
+	return user, err
+}
+
+// tracedPasswordResetUseCase PasswordResetUseCaseの各メソッドの呼び出しをspanとして記録する
+type tracedPasswordResetUseCase struct {
+	next PasswordResetUseCase
+}
+
+func (t *tracedPasswordResetUseCase) RequestPasswordReset(ctx context.Context, req *request.RequestPasswordReset) (string, time.Time, error) {
+	ctx, span := tracer.Start(ctx, "PasswordResetUseCase.RequestPasswordReset")
+	token, expiresAt, err := t.next.RequestPasswordReset(ctx, req)
+	tracing.EndSpan(span, err)
+	return token, expiresAt, err
+}
+
+func (t *tracedPasswordResetUseCase) ConfirmPasswordReset(ctx context.Context, req *request.ConfirmPasswordReset) error {
+	ctx, span := tracer.Start(ctx, "PasswordResetUseCase.ConfirmPasswordReset")
+	err := t.next.ConfirmPasswordReset(ctx, req)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+// tracedAPIKeyUseCase APIKeyUseCaseの各メソッドの呼び出しをspanとして記録する
+type tracedAPIKeyUseCase struct {
+	next APIKeyUseCase
+}
+
+func (t *tracedAPIKeyUseCase) IssueAPIKey(ctx context.Context, req *request.IssueAPIKey) (*domain.APIKey, string, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyUseCase.IssueAPIKey")
+	key, plain, err := t.next.IssueAPIKey(ctx, req)
+	if err == nil {
+		span.SetAttributes(apiKeyIDAttribute(key.ID()))
+	}
+	tracing.EndSpan(span, err)
+	return key, plain, err
+}
+
+func (t *tracedAPIKeyUseCase) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyUseCase.ListAPIKeys")
+	keys, err := t.next.ListAPIKeys(ctx)
+	tracing.EndSpan(span, err)
+	return keys, err
+}
+
+func (t *tracedAPIKeyUseCase) RevokeAPIKey(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyUseCase.RevokeAPIKey", trace.WithAttributes(apiKeyIDAttribute(id)))
+	key, err := t.next.RevokeAPIKey(ctx, id)
+	tracing.EndSpan(span, err)
+	return key, err
+}
+
+func (t *tracedAPIKeyUseCase) RotateAPIKey(ctx context.Context, id uuid.UUID, req *request.RotateAPIKey) (*domain.APIKey, string, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyUseCase.RotateAPIKey", trace.WithAttributes(apiKeyIDAttribute(id)))
+	key, plain, err := t.next.RotateAPIKey(ctx, id, req)
+	tracing.EndSpan(span, err)
+	return key, plain, err
+}
+
+func (t *tracedAPIKeyUseCase) Authenticate(ctx context.Context, plain string) (*domain.APIKey, error) {
+	ctx, span := tracer.Start(ctx, "APIKeyUseCase.Authenticate")
+	key, err := t.next.Authenticate(ctx, plain)
+	if err == nil {
+		span.SetAttributes(apiKeyIDAttribute(key.ID()))
+	}
+	tracing.EndSpan(span, err)
+	return key, err
+}
+
+func (t *tracedAPIKeyUseCase) RegisterBootstrapKey(ctx context.Context, plain string) error {
+	ctx, span := tracer.Start(ctx, "APIKeyUseCase.RegisterBootstrapKey")
+	err := t.next.RegisterBootstrapKey(ctx, plain)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+// tracedAuthUseCase AuthUseCaseの各メソッドの呼び出しをspanとして記録する
+type tracedAuthUseCase struct {
+	next AuthUseCase
+}
+
+func (t *tracedAuthUseCase) Login(ctx context.Context, req *request.Login) (*TokenPair, error) {
+	// メールアドレスは個人情報のため記録しない
+	ctx, span := tracer.Start(ctx, "AuthUseCase.Login")
+	pair, err := t.next.Login(ctx, req)
+	tracing.EndSpan(span, err)
+	return pair, err
+}
+
+func (t *tracedAuthUseCase) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	ctx, span := tracer.Start(ctx, "AuthUseCase.Refresh")
+	pair, err := t.next.Refresh(ctx, refreshToken)
+	tracing.EndSpan(span, err)
+	return pair, err
+}
+
+func (t *tracedAuthUseCase) Logout(ctx context.Context, refreshToken string) error {
+	ctx, span := tracer.Start(ctx, "AuthUseCase.Logout")
+	err := t.next.Logout(ctx, refreshToken)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func apiKeyIDAttribute(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("api_key.id", id.String())
+}
+
+func userIDAttribute(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("user.id", id.String())
+}
+
+// tracedAuditUseCase AuditUseCaseの各メソッドの呼び出しをspanとして記録する
+type tracedAuditUseCase struct {
+	next AuditUseCase
+}
+
+func (t *tracedAuditUseCase) ListAuditEvents(ctx context.Context, q *query.ListAuditEvents) (*AuditEventList, error) {
+	ctx, span := tracer.Start(ctx, "AuditUseCase.ListAuditEvents", trace.WithAttributes(
+		attribute.Int("audit.limit", q.Limit),
+		attribute.Bool("audit.cursor", q.Cursor != ""),
+	))
+	list, err := t.next.ListAuditEvents(ctx, q)
+	if err == nil {
+		span.SetAttributes(attribute.Int("audit.count", len(list.Events)))
+	}
+	tracing.EndSpan(span, err)
+	return list, err
+}
+
+// tracedWebhookUseCase WebhookUseCaseの各メソッドの呼び出しをspanとして記録する
+type tracedWebhookUseCase struct {
+	next WebhookUseCase
+}
+
+func (t *tracedWebhookUseCase) CreateWebhook(ctx context.Context, req *request.CreateWebhook) (*domain.WebhookSubscription, error) {
+	ctx, span := tracer.Start(ctx, "WebhookUseCase.CreateWebhook")
+	subscription, err := t.next.CreateWebhook(ctx, req)
+	if err == nil {
+		span.SetAttributes(webhookIDAttribute(subscription.ID()))
+	}
+	tracing.EndSpan(span, err)
+	return subscription, err
+}
+
+func (t *tracedWebhookUseCase) GetWebhook(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	ctx, span := tracer.Start(ctx, "WebhookUseCase.GetWebhook", trace.WithAttributes(webhookIDAttribute(id)))
+	subscription, err := t.next.GetWebhook(ctx, id)
+	tracing.EndSpan(span, err)
+	return subscription, err
+}
+
+func (t *tracedWebhookUseCase) ListWebhooks(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	ctx, span := tracer.Start(ctx, "WebhookUseCase.ListWebhooks")
+	subscriptions, err := t.next.ListWebhooks(ctx)
+	tracing.EndSpan(span, err)
+	return subscriptions, err
+}
+
+func (t *tracedWebhookUseCase) UpdateWebhook(ctx context.Context, id uuid.UUID, req *request.UpdateWebhook) (*domain.WebhookSubscription, error) {
+	ctx, span := tracer.Start(ctx, "WebhookUseCase.UpdateWebhook", trace.WithAttributes(webhookIDAttribute(id)))
+	subscription, err := t.next.UpdateWebhook(ctx, id, req)
+	tracing.EndSpan(span, err)
+	return subscription, err
+}
+
+func (t *tracedWebhookUseCase) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracer.Start(ctx, "WebhookUseCase.DeleteWebhook", trace.WithAttributes(webhookIDAttribute(id)))
+	err := t.next.DeleteWebhook(ctx, id)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (t *tracedWebhookUseCase) ListDeliveries(ctx context.Context, id uuid.UUID, q *query.ListWebhookDeliveries) (*WebhookDeliveryList, error) {
+	ctx, span := tracer.Start(ctx, "WebhookUseCase.ListDeliveries", trace.WithAttributes(
+		webhookIDAttribute(id),
+		attribute.Int("webhook.deliveries.limit", q.Limit),
+		attribute.Bool("webhook.deliveries.cursor", q.Cursor != ""),
+	))
+	list, err := t.next.ListDeliveries(ctx, id, q)
+	if err == nil {
+		span.SetAttributes(attribute.Int("webhook.deliveries.count", len(list.Deliveries)))
+	}
+	tracing.EndSpan(span, err)
+	return list, err
+}
+
+func (t *tracedWebhookUseCase) GetDelivery(ctx context.Context, id, deliveryID uuid.UUID) (*WebhookDeliveryDetail, error) {
+	ctx, span := tracer.Start(ctx, "WebhookUseCase.GetDelivery", trace.WithAttributes(
+		webhookIDAttribute(id),
+		webhookDeliveryIDAttribute(deliveryID),
+	))
+	detail, err := t.next.GetDelivery(ctx, id, deliveryID)
+	tracing.EndSpan(span, err)
+	return detail, err
+}
+
+func (t *tracedWebhookUseCase) ReplayDelivery(ctx context.Context, id, deliveryID uuid.UUID) (*domain.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "WebhookUseCase.ReplayDelivery", trace.WithAttributes(
+		webhookIDAttribute(id),
+		webhookDeliveryIDAttribute(deliveryID),
+	))
+	delivery, err := t.next.ReplayDelivery(ctx, id, deliveryID)
+	tracing.EndSpan(span, err)
+	return delivery, err
+}
+
+func webhookIDAttribute(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("webhook.id", id.String())
+}
+
+func webhookDeliveryIDAttribute(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("webhook.delivery.id", id.String())
+}
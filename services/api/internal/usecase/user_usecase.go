@@ -4,10 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
 	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/query"
 	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/policy"
 	"github.com/tokane888/test-mcp/services/api/internal/repository"
 	"go.uber.org/zap"
 )
@@ -15,27 +21,107 @@ import (
 var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
+	// ErrConflict 読み込みから更新までの間に他のリクエストによって更新された
+	ErrConflict = errors.New("user was modified concurrently")
+	// ErrPreconditionFailed 指定されたversion(If-Match)が現在のversionと一致しない
+	ErrPreconditionFailed = errors.New("user version does not match")
+	ErrInvalidSortField   = errors.New("invalid sort field")
+	ErrUserNotDeleted     = errors.New("user is not deleted")
+	// ErrForbidden 操作主体のroleでは許可されていない操作
+	ErrForbidden   = errors.New("operation is not permitted")
+	ErrInvalidRole = errors.New("invalid role")
 )
 
 type UserUseCase interface {
 	CreateUser(ctx context.Context, req *request.CreateUser) (*domain.User, error)
-	ListUsers(ctx context.Context, limit, offset int) ([]*domain.User, int, error)
-	DeleteUser(ctx context.Context, id uuid.UUID) error
+	GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	ListUsers(ctx context.Context, q *query.ListUsers) (*UserList, error)
+	// expectedVersionsがnilでない場合、現在のversionがいずれかと一致する場合のみ更新する
+	UpdateUser(ctx context.Context, id uuid.UUID, expectedVersions []int, req *request.UpdateUser) (*domain.User, error)
+	ChangePassword(ctx context.Context, id uuid.UUID, req *request.ChangePassword) error
+	DeleteUser(ctx context.Context, id uuid.UUID, expectedVersions []int) error
+	RestoreUser(ctx context.Context, id uuid.UUID, expectedVersions []int) (*domain.User, error)
+	// HardDeleteUser 管理者向け。論理削除済みかに関わらず行を物理削除する
+	HardDeleteUser(ctx context.Context, id uuid.UUID) error
+	// ChangeRole 管理者向け
+	ChangeRole(ctx context.Context, id uuid.UUID, expectedVersions []int, role domain.Role) (*domain.User, error)
+}
+
+// Authorizer 操作主体(contextに保持)に操作が許可されているかを判定する。policy.Policyが満たす
+type Authorizer interface {
+	// targetは操作対象のユーザー。対象を持たない操作ではnil
+	Authorize(ctx context.Context, action policy.Action, target *domain.User) error
+}
+
+// UserMetrics ユーザー関連の業務指標の記録先。トランザクションのcommit後に呼び出す
+type UserMetrics interface {
+	UserCreated()
+	UserDeleted()
+	UserHardDeleted()
+	UserRestored()
+}
+
+type UserList struct {
+	Users []*domain.User
+	// SkipTotal指定時はnil
+	Total *int
+	// 次のページが存在しない場合は空文字
+	NextCursor string
 }
 
 type userUseCase struct {
-	userRepo repository.UserRepository
-	logger   *zap.Logger
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	auditRepo        repository.AuditEventRepository
+	outboxRepo       repository.OutboxRepository
+	txManager        repository.TxManager
+	authorizer       Authorizer
+	metrics          UserMetrics
+	logger           *zap.Logger
 }
 
-func NewUserUseCase(userRepo repository.UserRepository, logger *zap.Logger) UserUseCase {
-	return &userUseCase{
-		userRepo: userRepo,
-		logger:   logger,
-	}
+func NewUserUseCase(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	auditRepo repository.AuditEventRepository,
+	outboxRepo repository.OutboxRepository,
+	txManager repository.TxManager,
+	authorizer Authorizer,
+	metrics UserMetrics,
+	logger *zap.Logger,
+) UserUseCase {
+	return &tracedUserUseCase{next: &userUseCase{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		auditRepo:        auditRepo,
+		outboxRepo:       outboxRepo,
+		txManager:        txManager,
+		authorizer:       authorizer,
+		metrics:          metrics,
+		logger:           logger,
+	}}
 }
 
 func (uc *userUseCase) CreateUser(ctx context.Context, req *request.CreateUser) (*domain.User, error) {
+	var user *domain.User
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = uc.createUser(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.metrics.UserCreated()
+	return user, nil
+}
+
+func (uc *userUseCase) createUser(ctx context.Context, req *request.CreateUser) (*domain.User, error) {
+	if err := uc.authorize(ctx, policy.ActionUserCreate, nil); err != nil {
+		return nil, err
+	}
+
 	// Check if user already exists
 	exists, err := uc.userRepo.ExistsByEmail(ctx, req.Email)
 	if err != nil {
@@ -59,19 +145,184 @@ func (uc *userUseCase) CreateUser(ctx context.Context, req *request.CreateUser)
 		return nil, fmt.Errorf("failed to save user: %w", err)
 	}
 
+	if err := uc.publishEvents(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := recordAuditEvent(ctx, uc.auditRepo, domain.AuditActionUserCreated, user.ID(), nil, domain.UserAuditFields(user)); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (uc *userUseCase) GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	user, err := uc.userRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if err := uc.authorize(ctx, policy.ActionUserRead, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (uc *userUseCase) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	// 他のユーザーのメールアドレスの存在を確認できないよう、検索前に判定する
+	if err := uc.authorize(ctx, policy.ActionUserList, nil); err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user by email: %w", err)
+	}
+
+	return user, nil
+}
+
+func (uc *userUseCase) ListUsers(ctx context.Context, q *query.ListUsers) (*UserList, error) {
+	if err := uc.authorize(ctx, policy.ActionUserList, nil); err != nil {
+		return nil, err
+	}
+
+	params := repository.UserListParams{
+		Filter: repository.UserFilter{
+			EmailDomain:      q.EmailDomain,
+			UsernamePrefix:   q.UsernamePrefix,
+			UsernameContains: q.UsernameContains,
+			CreatedFrom:      q.CreatedFrom,
+			CreatedTo:        q.CreatedTo,
+			IncludeDeleted:   q.IncludeDeleted,
+		},
+		Sort: repository.UserSort{
+			Field: repository.UserSortField(q.Sort),
+			Desc:  q.Order == "desc",
+		},
+		Limit:     q.Limit,
+		Offset:    q.Offset,
+		SkipTotal: q.SkipTotal,
+	}
+	if q.Cursor != "" {
+		after, err := decodeUserCursor(q.Cursor, params.Sort)
+		if err != nil {
+			return nil, err
+		}
+		params.After = after
+	}
+
+	result, err := uc.userRepo.List(ctx, params)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidSortField) {
+			return nil, ErrInvalidSortField
+		}
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return &UserList{
+		Users:      result.Users,
+		Total:      result.Total,
+		NextCursor: encodeUserCursor(result.NextCursor, params.Sort),
+	}, nil
+}
+
+func (uc *userUseCase) UpdateUser(ctx context.Context, id uuid.UUID, expectedVersions []int, req *request.UpdateUser) (*domain.User, error) {
+	var user *domain.User
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = uc.updateUser(ctx, id, expectedVersions, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
-func (uc *userUseCase) ListUsers(ctx context.Context, limit, offset int) ([]*domain.User, int, error) {
-	users, total, err := uc.userRepo.List(ctx, limit, offset)
+func (uc *userUseCase) updateUser(ctx context.Context, id uuid.UUID, expectedVersions []int, req *request.UpdateUser) (*domain.User, error) {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, id)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if err := uc.authorize(ctx, policy.ActionUserUpdate, user); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(user, expectedVersions); err != nil {
+		return nil, err
+	}
+	before := domain.UserAuditFields(user)
+	changed := false
+
+	if req.Email != nil && *req.Email != user.Email() {
+		// Check if email is already used by another user
+		// メールアドレスは大文字小文字を区別せず一意のため、大文字小文字のみの変更は自身と重複しない
+		if !strings.EqualFold(*req.Email, user.Email()) {
+			exists, err := uc.userRepo.ExistsByEmail(ctx, *req.Email)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check if user exists: %w", err)
+			}
+			if exists {
+				return nil, ErrUserAlreadyExists
+			}
+		}
+		if err := user.UpdateEmail(*req.Email); err != nil {
+			return nil, fmt.Errorf("failed to update email: %w", err)
+		}
+		changed = true
+	}
+
+	if req.Username != nil && *req.Username != user.Username() {
+		if err := user.UpdateUsername(*req.Username); err != nil {
+			return nil, fmt.Errorf("failed to update username: %w", err)
+		}
+		changed = true
+	}
+	// 変更が無い場合はversionを進めず、監査ログも記録しない
+	if !changed {
+		return user, nil
+	}
+
+	// Update in repository
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		if errors.Is(err, repository.ErrUserAlreadyExists) {
+			return nil, ErrUserAlreadyExists
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, conflictError(expectedVersions)
+		}
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if err := uc.publishEvents(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := recordAuditEvent(ctx, uc.auditRepo, domain.AuditActionUserUpdated, user.ID(), before, domain.UserAuditFields(user)); err != nil {
+		return nil, err
 	}
 
-	return users, total, nil
+	return user, nil
+}
+
+func (uc *userUseCase) ChangePassword(ctx context.Context, id uuid.UUID, req *request.ChangePassword) error {
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return uc.changePassword(ctx, id, req)
+	})
 }
 
-func (uc *userUseCase) DeleteUser(ctx context.Context, id uuid.UUID) error {
+func (uc *userUseCase) changePassword(ctx context.Context, id uuid.UUID, req *request.ChangePassword) error {
 	// Find user
 	user, err := uc.userRepo.FindByID(ctx, id)
 	if err != nil {
@@ -80,17 +331,296 @@ func (uc *userUseCase) DeleteUser(ctx context.Context, id uuid.UUID) error {
 		}
 		return fmt.Errorf("failed to find user: %w", err)
 	}
+	if err := uc.authorize(ctx, policy.ActionUserChangePassword, user); err != nil {
+		return err
+	}
+	before := domain.UserAuditFields(user)
+
+	if err := user.ChangePassword(req.CurrentPassword, req.NewPassword); err != nil {
+		return fmt.Errorf("failed to change password: %w", err)
+	}
+
+	if err := uc.userRepo.UpdatePassword(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// 漏洩したパスワードでログインされていた場合に備え、既存のセッションを失効させる
+	if err := uc.refreshTokenRepo.RevokeAllForUser(ctx, user.ID(), time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	// パスワードハッシュは記録せず、versionの変化のみ残る
+	if err := uc.publishEvents(ctx, user); err != nil {
+		return err
+	}
+	return recordAuditEvent(ctx, uc.auditRepo, domain.AuditActionUserPasswordChanged, user.ID(), before, domain.UserAuditFields(user))
+}
+
+func (uc *userUseCase) DeleteUser(ctx context.Context, id uuid.UUID, expectedVersions []int) error {
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return uc.deleteUser(ctx, id, expectedVersions)
+	})
+	if err != nil {
+		return err
+	}
+	uc.metrics.UserDeleted()
+	return nil
+}
+
+func (uc *userUseCase) deleteUser(ctx context.Context, id uuid.UUID, expectedVersions []int) error {
+	// Find user
+	user, err := uc.userRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if err := uc.authorize(ctx, policy.ActionUserDelete, user); err != nil {
+		return err
+	}
+	if err := checkVersion(user, expectedVersions); err != nil {
+		return err
+	}
+	before := domain.UserAuditFields(user)
 
 	// Mark as deleted
 	user.Delete()
 
 	// Update in repository
+	// versionを条件に更新するため、FindByIDの後に他のリクエストが更新・削除した場合はErrConflictとなる
 	if err := uc.userRepo.Update(ctx, user); err != nil {
 		if errors.Is(err, repository.ErrUserNotFound) {
 			return ErrUserNotFound
 		}
+		if errors.Is(err, repository.ErrConflict) {
+			return conflictError(expectedVersions)
+		}
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
+	if err := uc.publishEvents(ctx, user); err != nil {
+		return err
+	}
+	return recordAuditEvent(ctx, uc.auditRepo, domain.AuditActionUserDeleted, user.ID(), before, domain.UserAuditFields(user))
+}
+
+func (uc *userUseCase) RestoreUser(ctx context.Context, id uuid.UUID, expectedVersions []int) (*domain.User, error) {
+	var user *domain.User
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = uc.restoreUser(ctx, id, expectedVersions)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	uc.metrics.UserRestored()
+	return user, nil
+}
+
+func (uc *userUseCase) restoreUser(ctx context.Context, id uuid.UUID, expectedVersions []int) (*domain.User, error) {
+	// Find user including deleted
+	user, err := uc.userRepo.FindByIDIncludingDeleted(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if err := uc.authorize(ctx, policy.ActionUserRestore, user); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(user, expectedVersions); err != nil {
+		return nil, err
+	}
+	before := domain.UserAuditFields(user)
+
+	if err := user.Restore(); err != nil {
+		if errors.Is(err, domain.ErrUserNotDeleted) {
+			return nil, ErrUserNotDeleted
+		}
+		return nil, fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	// 削除後に同じメールアドレスで別ユーザーが作成されている可能性があるため再確認する
+	exists, err := uc.userRepo.ExistsByEmail(ctx, user.Email())
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if user exists: %w", err)
+	}
+	if exists {
+		return nil, ErrUserAlreadyExists
+	}
+
+	// Update in repository
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		if errors.Is(err, repository.ErrUserAlreadyExists) {
+			return nil, ErrUserAlreadyExists
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, conflictError(expectedVersions)
+		}
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if err := uc.publishEvents(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := recordAuditEvent(ctx, uc.auditRepo, domain.AuditActionUserRestored, user.ID(), before, domain.UserAuditFields(user)); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (uc *userUseCase) HardDeleteUser(ctx context.Context, id uuid.UUID) error {
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		return uc.hardDeleteUser(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	uc.metrics.UserHardDeleted()
+	pkglogger.FromContext(ctx).Info("user hard deleted", zap.String("user_id", id.String()))
 	return nil
 }
+
+func (uc *userUseCase) hardDeleteUser(ctx context.Context, id uuid.UUID) error {
+	if err := uc.authorize(ctx, policy.ActionUserHardDelete, nil); err != nil {
+		return err
+	}
+
+	// 削除後は参照できないため、監査ログ用に削除前の値を取得しておく
+	user, err := uc.userRepo.FindByIDIncludingDeleted(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	user.HardDelete()
+	if err := uc.userRepo.HardDelete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to hard delete user: %w", err)
+	}
+
+	if err := uc.publishEvents(ctx, user); err != nil {
+		return err
+	}
+	return recordAuditEvent(ctx, uc.auditRepo, domain.AuditActionUserHardDeleted, id, domain.UserAuditFields(user), nil)
+}
+
+func (uc *userUseCase) ChangeRole(ctx context.Context, id uuid.UUID, expectedVersions []int, role domain.Role) (*domain.User, error) {
+	var user *domain.User
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = uc.changeRole(ctx, id, expectedVersions, role)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	pkglogger.FromContext(ctx).Info("user role changed",
+		zap.String("user_id", id.String()),
+		zap.String("role", string(role)),
+	)
+	return user, nil
+}
+
+func (uc *userUseCase) changeRole(ctx context.Context, id uuid.UUID, expectedVersions []int, role domain.Role) (*domain.User, error) {
+	user, err := uc.userRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if err := uc.authorize(ctx, policy.ActionUserChangeRole, user); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(user, expectedVersions); err != nil {
+		return nil, err
+	}
+	before := domain.UserAuditFields(user)
+
+	if err := user.ChangeRole(role); err != nil {
+		if errors.Is(err, domain.ErrInvalidRole) {
+			return nil, ErrInvalidRole
+		}
+		return nil, fmt.Errorf("failed to change role: %w", err)
+	}
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, conflictError(expectedVersions)
+		}
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if err := uc.publishEvents(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := recordAuditEvent(ctx, uc.auditRepo, domain.AuditActionUserRoleChanged, user.ID(), before, domain.UserAuditFields(user)); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// publishEvents userの保存後、同じトランザクション内で記録済みのドメインイベントをoutboxへ保存する
+func (uc *userUseCase) publishEvents(ctx context.Context, user *domain.User) error {
+	events := user.PullEvents()
+	if len(events) == 0 {
+		return nil
+	}
+	if err := uc.outboxRepo.Append(ctx, events); err != nil {
+		return fmt.Errorf("failed to append domain events: %w", err)
+	}
+	return nil
+}
+
+func (uc *userUseCase) authorize(ctx context.Context, action policy.Action, target *domain.User) error {
+	return authorize(ctx, uc.authorizer, action, target)
+}
+
+// authorize policyで拒否された場合はErrForbiddenを返す
+func authorize(ctx context.Context, authorizer Authorizer, action policy.Action, target *domain.User) error {
+	if err := authorizer.Authorize(ctx, action, target); err != nil {
+		if errors.Is(err, policy.ErrForbidden) {
+			return ErrForbidden
+		}
+		return fmt.Errorf("failed to authorize: %w", err)
+	}
+	return nil
+}
+
+func checkVersion(user *domain.User, expectedVersions []int) error {
+	if expectedVersions != nil && !slices.Contains(expectedVersions, user.Version()) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// conflictError If-Matchが指定されていた場合、競合はversion不一致として扱う
+func conflictError(expectedVersions []int) error {
+	if expectedVersions != nil {
+		return ErrPreconditionFailed
+	}
+	return ErrConflict
+}
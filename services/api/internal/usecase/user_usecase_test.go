@@ -0,0 +1,68 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/infrastructure/memory"
+	"github.com/tokane888/test-mcp/services/api/internal/policy"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"github.com/tokane888/test-mcp/services/api/internal/usecase"
+	"go.uber.org/zap"
+)
+
+type nopUserMetrics struct{}
+
+func (nopUserMetrics) UserCreated()     {}
+func (nopUserMetrics) UserDeleted()     {}
+func (nopUserMetrics) UserHardDeleted() {}
+func (nopUserMetrics) UserRestored()    {}
+
+// 現在と同じ値の更新はversionを進めず、監査ログも記録しない
+func TestUpdateUserWithoutChanges(t *testing.T) {
+	ctx := asRole(domain.RoleAdmin)
+	users := memory.NewUserRepository()
+	audits := memory.NewAuditEventRepository()
+	uc := newUserUseCase(users, audits)
+	target := createUser(t, users, "target@example.com", domain.RoleSelf)
+
+	email, username := target.Email(), target.Username()
+	updated, err := uc.UpdateUser(ctx, target.ID(), nil, &request.UpdateUser{Email: &email, Username: &username})
+	if err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if updated.Version() != target.Version() {
+		t.Errorf("version = %d, want %d", updated.Version(), target.Version())
+	}
+	stored, err := users.FindByID(context.Background(), target.ID())
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if stored.Version() != target.Version() {
+		t.Errorf("stored version = %d, want %d", stored.Version(), target.Version())
+	}
+	events, err := audits.List(context.Background(), repository.AuditEventListParams{Limit: 10})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(events.Events) != 0 {
+		t.Errorf("audit events = %d, want 0", len(events.Events))
+	}
+}
+
+func newUserUseCase(users repository.UserRepository, audits repository.AuditEventRepository) usecase.UserUseCase {
+	refreshTokens := memory.NewRefreshTokenRepository()
+	outbox := memory.NewOutboxRepository()
+	return usecase.NewUserUseCase(
+		users,
+		refreshTokens,
+		audits,
+		outbox,
+		memory.NewTxManager(users, refreshTokens, audits, outbox),
+		policy.NewPolicy(),
+		nopUserMetrics{},
+		zap.NewNop(),
+	)
+}
@@ -0,0 +1,292 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/api/internal/domain"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/query"
+	"github.com/tokane888/test-mcp/services/api/internal/dto/request"
+	"github.com/tokane888/test-mcp/services/api/internal/policy"
+	"github.com/tokane888/test-mcp/services/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrWebhookDeliveryPending 配信待ちの配信は再送できない
+	ErrWebhookDeliveryPending = errors.New("webhook delivery is still pending")
+	ErrInvalidWebhookRequest  = errors.New("invalid webhook request")
+)
+
+// WebhookUseCase ドメインイベントを通知するwebhookの購読の管理。
+// 配信はservices/batchのoutbox-relay、webhook-dispatcherが行う
+type WebhookUseCase interface {
+	// CreateWebhook 署名用のシークレットを取得できるのはこの時のみ
+	CreateWebhook(ctx context.Context, req *request.CreateWebhook) (*domain.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context) ([]*domain.WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, id uuid.UUID, req *request.UpdateWebhook) (*domain.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, id uuid.UUID, q *query.ListWebhookDeliveries) (*WebhookDeliveryList, error)
+	GetDelivery(ctx context.Context, id, deliveryID uuid.UUID) (*WebhookDeliveryDetail, error)
+	// ReplayDelivery 配信済み、またはdead letterの配信を再送待ちに戻す
+	ReplayDelivery(ctx context.Context, id, deliveryID uuid.UUID) (*domain.WebhookDelivery, error)
+}
+
+type WebhookDeliveryList struct {
+	Deliveries []*domain.WebhookDelivery
+	// 次のページが存在しない場合は空文字
+	NextCursor string
+}
+
+type WebhookDeliveryDetail struct {
+	Delivery *domain.WebhookDelivery
+	// 送信日時の新しい順
+	Attempts []domain.WebhookDeliveryAttempt
+}
+
+type webhookUseCase struct {
+	subscriptionRepo repository.WebhookSubscriptionRepository
+	deliveryRepo     repository.WebhookDeliveryRepository
+	txManager        repository.TxManager
+	authorizer       Authorizer
+	logger           *zap.Logger
+}
+
+func NewWebhookUseCase(
+	subscriptionRepo repository.WebhookSubscriptionRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	txManager repository.TxManager,
+	authorizer Authorizer,
+	logger *zap.Logger,
+) WebhookUseCase {
+	return &tracedWebhookUseCase{next: &webhookUseCase{
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		txManager:        txManager,
+		authorizer:       authorizer,
+		logger:           logger,
+	}}
+}
+
+func (uc *webhookUseCase) CreateWebhook(ctx context.Context, req *request.CreateWebhook) (*domain.WebhookSubscription, error) {
+	if err := authorize(ctx, uc.authorizer, policy.ActionWebhookManage, nil); err != nil {
+		return nil, err
+	}
+
+	subscription, err := domain.NewWebhookSubscription(req.URL, req.EventTypes, req.Secret)
+	if err != nil {
+		return nil, webhookRequestError(err)
+	}
+	if err := uc.subscriptionRepo.Create(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to save webhook subscription: %w", err)
+	}
+
+	pkglogger.FromContext(ctx).Info("webhook subscription created",
+		zap.String("webhook_id", subscription.ID().String()),
+		zap.Strings("event_types", subscription.EventTypes()),
+	)
+	return subscription, nil
+}
+
+func (uc *webhookUseCase) GetWebhook(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	if err := authorize(ctx, uc.authorizer, policy.ActionWebhookRead, nil); err != nil {
+		return nil, err
+	}
+	return uc.findSubscription(ctx, id)
+}
+
+func (uc *webhookUseCase) ListWebhooks(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	if err := authorize(ctx, uc.authorizer, policy.ActionWebhookRead, nil); err != nil {
+		return nil, err
+	}
+
+	subscriptions, err := uc.subscriptionRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (uc *webhookUseCase) UpdateWebhook(ctx context.Context, id uuid.UUID, req *request.UpdateWebhook) (*domain.WebhookSubscription, error) {
+	var subscription *domain.WebhookSubscription
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		subscription, err = uc.updateWebhook(ctx, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (uc *webhookUseCase) updateWebhook(ctx context.Context, id uuid.UUID, req *request.UpdateWebhook) (*domain.WebhookSubscription, error) {
+	if err := authorize(ctx, uc.authorizer, policy.ActionWebhookManage, nil); err != nil {
+		return nil, err
+	}
+	subscription, err := uc.findSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := subscription.UpdateURL(*req.URL); err != nil {
+			return nil, webhookRequestError(err)
+		}
+	}
+	if req.EventTypes != nil {
+		if err := subscription.UpdateEventTypes(req.EventTypes); err != nil {
+			return nil, webhookRequestError(err)
+		}
+	}
+	if req.Active != nil {
+		subscription.SetActive(*req.Active)
+	}
+
+	if err := uc.subscriptionRepo.Update(ctx, subscription); err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (uc *webhookUseCase) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	if err := authorize(ctx, uc.authorizer, policy.ActionWebhookManage, nil); err != nil {
+		return err
+	}
+
+	if err := uc.subscriptionRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return ErrWebhookNotFound
+		}
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	pkglogger.FromContext(ctx).Info("webhook subscription deleted", zap.String("webhook_id", id.String()))
+	return nil
+}
+
+func (uc *webhookUseCase) ListDeliveries(ctx context.Context, id uuid.UUID, q *query.ListWebhookDeliveries) (*WebhookDeliveryList, error) {
+	if err := authorize(ctx, uc.authorizer, policy.ActionWebhookRead, nil); err != nil {
+		return nil, err
+	}
+	// 存在しない購読の場合は空の一覧ではなく404とする
+	if _, err := uc.findSubscription(ctx, id); err != nil {
+		return nil, err
+	}
+
+	params := repository.WebhookDeliveryListParams{
+		SubscriptionID: id,
+		Status:         domain.WebhookDeliveryStatus(q.Status),
+		Limit:          q.Limit,
+	}
+	if q.Cursor != "" {
+		after, err := decodeWebhookDeliveryCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		params.After = after
+	}
+
+	result, err := uc.deliveryRepo.List(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return &WebhookDeliveryList{
+		Deliveries: result.Deliveries,
+		NextCursor: encodeWebhookDeliveryCursor(result.NextCursor),
+	}, nil
+}
+
+func (uc *webhookUseCase) GetDelivery(ctx context.Context, id, deliveryID uuid.UUID) (*WebhookDeliveryDetail, error) {
+	if err := authorize(ctx, uc.authorizer, policy.ActionWebhookRead, nil); err != nil {
+		return nil, err
+	}
+
+	delivery, err := uc.findDelivery(ctx, id, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := uc.deliveryRepo.ListAttempts(ctx, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook delivery attempts: %w", err)
+	}
+	return &WebhookDeliveryDetail{Delivery: delivery, Attempts: attempts}, nil
+}
+
+func (uc *webhookUseCase) ReplayDelivery(ctx context.Context, id, deliveryID uuid.UUID) (*domain.WebhookDelivery, error) {
+	if err := authorize(ctx, uc.authorizer, policy.ActionWebhookManage, nil); err != nil {
+		return nil, err
+	}
+
+	delivery, err := uc.findDelivery(ctx, id, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	previousStatus := delivery.Status()
+	if err := delivery.Replay(); err != nil {
+		if errors.Is(err, domain.ErrWebhookDeliveryPending) {
+			return nil, ErrWebhookDeliveryPending
+		}
+		return nil, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	if err := uc.deliveryRepo.Replay(ctx, delivery, previousStatus); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			// 読み込み後にwebhook-dispatcher、または他のリクエストが状態を変更した
+			return nil, ErrConflict
+		}
+		if errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	pkglogger.FromContext(ctx).Info("webhook delivery replayed",
+		zap.String("webhook_id", id.String()),
+		zap.String("delivery_id", deliveryID.String()),
+	)
+	return delivery, nil
+}
+
+func (uc *webhookUseCase) findSubscription(ctx context.Context, id uuid.UUID) (*domain.WebhookSubscription, error) {
+	subscription, err := uc.subscriptionRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to find webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (uc *webhookUseCase) findDelivery(ctx context.Context, id, deliveryID uuid.UUID) (*domain.WebhookDelivery, error) {
+	delivery, err := uc.deliveryRepo.FindByID(ctx, id, deliveryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to find webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// webhookRequestError ドメインのバリデーションエラーをErrInvalidWebhookRequestとしてwrapする
+func webhookRequestError(err error) error {
+	if errors.Is(err, domain.ErrInvalidWebhookURL) ||
+		errors.Is(err, domain.ErrWebhookURLNotPublic) ||
+		errors.Is(err, domain.ErrInvalidWebhookEventType) ||
+		errors.Is(err, domain.ErrWebhookEventTypesEmpty) ||
+		errors.Is(err, domain.ErrWebhookSecretTooShort) {
+		return fmt.Errorf("%w: %w", ErrInvalidWebhookRequest, err)
+	}
+	return fmt.Errorf("failed to create webhook subscription entity: %w", err)
+}
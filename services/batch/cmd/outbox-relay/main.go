@@ -0,0 +1,72 @@
+// outbox-relay services/apiがoutbox_eventsに保存したドメインイベントを設定された配信先へ配信する
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/batch/internal/config"
+	"github.com/tokane888/test-mcp/services/batch/internal/db"
+	"github.com/tokane888/test-mcp/services/batch/internal/outbox"
+	"go.uber.org/zap"
+)
+
+// アプリのversion。デフォルトは開発版。cloud上ではbuild時に-ldflagsフラグ経由でバージョンを埋め込む
+var version = "dev"
+
+func main() {
+	once := flag.Bool("once", false, "未配信のイベントを1回分配信して終了する(cron等での定期実行向け)")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(version)
+	if err != nil {
+		log.Fatal("failed to load config: ", err)
+	}
+	logger := pkglogger.NewLogger(cfg.Logger)
+	//nolint: errcheck
+	defer logger.Sync()
+
+	// 設定誤りはDBへの接続より前に検出する
+	if err := cfg.OutboxConfig.Validate(); err != nil {
+		logger.Fatal("invalid outbox sink configuration", zap.Error(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	database, err := db.Connect(ctx, &cfg.DatabaseConfig, logger)
+	if err != nil {
+		logger.Fatal("failed to connect to database", zap.Error(err))
+	}
+	defer func() {
+		if closeErr := database.Close(); closeErr != nil {
+			logger.Error("failed to close database connection", zap.Error(closeErr))
+		}
+	}()
+
+	sinks, err := outbox.NewSinks(&cfg.OutboxConfig, database, logger)
+	if err != nil {
+		logger.Fatal("failed to configure outbox sinks", zap.Error(err))
+	}
+	relay := outbox.NewRelay(outbox.NewStore(database), sinks, &cfg.OutboxConfig, logger)
+
+	if *once {
+		published, err := relay.RunOnce(ctx)
+		if err != nil {
+			logger.Fatal("failed to relay outbox events", zap.Error(err))
+		}
+		logger.Info("relayed outbox events", zap.Int("count", published))
+		return
+	}
+
+	logger.Info("starting outbox relay",
+		zap.Strings("sinks", cfg.OutboxConfig.Sinks),
+		zap.Duration("poll_interval", cfg.OutboxConfig.PollInterval),
+	)
+	relay.Run(ctx)
+	logger.Info("outbox relay exited")
+}
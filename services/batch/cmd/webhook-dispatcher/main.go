@@ -0,0 +1,63 @@
+// webhook-dispatcher outbox-relayが作成したwebhookの配信を購読先へ署名付きで送信する
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/batch/internal/config"
+	"github.com/tokane888/test-mcp/services/batch/internal/db"
+	"github.com/tokane888/test-mcp/services/batch/internal/webhook"
+	"go.uber.org/zap"
+)
+
+// アプリのversion。デフォルトは開発版。cloud上ではbuild時に-ldflagsフラグ経由でバージョンを埋め込む
+var version = "dev"
+
+func main() {
+	once := flag.Bool("once", false, "送信予定時刻を過ぎた配信を1回分送信して終了する(cron等での定期実行向け)")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig(version)
+	if err != nil {
+		log.Fatal("failed to load config: ", err)
+	}
+	logger := pkglogger.NewLogger(cfg.Logger)
+	//nolint: errcheck
+	defer logger.Sync()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	database, err := db.Connect(ctx, &cfg.DatabaseConfig, logger)
+	if err != nil {
+		logger.Fatal("failed to connect to database", zap.Error(err))
+	}
+	defer func() {
+		if closeErr := database.Close(); closeErr != nil {
+			logger.Error("failed to close database connection", zap.Error(closeErr))
+		}
+	}()
+
+	dispatcher := webhook.NewDispatcher(webhook.NewStore(database), &cfg.WebhookConfig, logger)
+
+	if *once {
+		sent, err := dispatcher.RunOnce(ctx)
+		if err != nil {
+			logger.Fatal("failed to dispatch webhook deliveries", zap.Error(err))
+		}
+		logger.Info("dispatched webhook deliveries", zap.Int("count", sent))
+		return
+	}
+
+	logger.Info("starting webhook dispatcher",
+		zap.Duration("poll_interval", cfg.WebhookConfig.PollInterval),
+		zap.Int("max_attempts", cfg.WebhookConfig.MaxAttempts),
+	)
+	dispatcher.Run(ctx)
+	logger.Info("webhook dispatcher exited")
+}
@@ -4,6 +4,7 @@ go 1.24
 
 require (
 	github.com/joho/godotenv v1.5.1
+	github.com/lib/pq v1.10.9
 	github.com/tokane888/test-mcp/pkg/logger v0.0.0
 	go.uber.org/zap v1.27.0
 )
@@ -1,18 +1,27 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/tokane888/test-mcp/pkg/logger"
+	"github.com/tokane888/test-mcp/services/batch/internal/db"
+	"github.com/tokane888/test-mcp/services/batch/internal/outbox"
+	"github.com/tokane888/test-mcp/services/batch/internal/webhook"
 )
 
 // Config 環境変数を読み取り、各struct向けのConfigを保持
 type Config struct {
-	Env    string
-	Logger logger.Config
-	// 必要に応じてDatabaseConfig等各structへ注入する設定追加
+	Env            string
+	Logger         logger.Config
+	DatabaseConfig db.Config
+	OutboxConfig   outbox.Config
+	WebhookConfig  webhook.Config
 }
 
 // LoadConfig loads environment variables into Config
@@ -24,6 +33,19 @@ func LoadConfig(version string) (*Config, error) {
 		return nil, fmt.Errorf("failed to load %s: %w", envFile, err)
 	}
 
+	dbConfig, err := loadDatabaseConfig()
+	if err != nil {
+		return nil, err
+	}
+	outboxConfig, err := loadOutboxConfig()
+	if err != nil {
+		return nil, err
+	}
+	webhookConfig, err := loadWebhookConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
 		Env: env,
 		Logger: logger.Config{
@@ -32,13 +54,215 @@ func LoadConfig(version string) (*Config, error) {
 			Level:      getEnv("LOG_LEVEL", "info"),
 			Format:     getEnv("LOG_FORMAT", "local"),
 		},
+		DatabaseConfig: *dbConfig,
+		OutboxConfig:   *outboxConfig,
+		WebhookConfig:  *webhookConfig,
 	}
 	return cfg, nil
 }
 
+func loadDatabaseConfig() (*db.Config, error) {
+	port, err := getIntEnv("DB_PORT", 5432)
+	if err != nil {
+		return nil, err
+	}
+	maxOpenConns, err := getIntEnv("DB_MAX_OPEN_CONNS", 5)
+	if err != nil {
+		return nil, err
+	}
+	maxIdleConns, err := getIntEnv("DB_MAX_IDLE_CONNS", 2)
+	if err != nil {
+		return nil, err
+	}
+	connectTimeout, err := getDurationEnv("DB_CONNECT_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	statementTimeout, err := getDurationEnv("DB_STATEMENT_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	connectRetries, err := getIntEnv("DB_CONNECT_RETRIES", 5)
+	if err != nil {
+		return nil, err
+	}
+	connectRetryInterval, err := getDurationEnv("DB_CONNECT_RETRY_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return &db.Config{
+		Host:                 getEnv("DB_HOST", "localhost"),
+		Port:                 port,
+		User:                 getEnv("DB_USER", "postgres"),
+		Password:             getEnv("DB_PASSWORD", "postgres"),
+		DBName:               getEnv("DB_NAME", "api_db"),
+		SSLMode:              getEnv("DB_SSLMODE", "disable"),
+		MaxOpenConns:         maxOpenConns,
+		MaxIdleConns:         maxIdleConns,
+		ConnectTimeout:       connectTimeout,
+		StatementTimeout:     statementTimeout,
+		ConnectRetries:       connectRetries,
+		ConnectRetryInterval: connectRetryInterval,
+	}, nil
+}
+
+func loadOutboxConfig() (*outbox.Config, error) {
+	pollInterval, err := getDurationEnv("OUTBOX_POLL_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	batchSize, err := getIntEnv("OUTBOX_BATCH_SIZE", 100)
+	if err != nil {
+		return nil, err
+	}
+	if pollInterval <= 0 || batchSize <= 0 {
+		return nil, errors.New("OUTBOX_POLL_INTERVAL and OUTBOX_BATCH_SIZE must be positive")
+	}
+	httpSinkTimeout, err := getDurationEnv("OUTBOX_HTTP_SINK_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	maxAttempts, err := getIntEnv("OUTBOX_MAX_ATTEMPTS", 10)
+	if err != nil {
+		return nil, err
+	}
+	backoffBase, err := getDurationEnv("OUTBOX_BACKOFF_BASE", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	backoffMax, err := getDurationEnv("OUTBOX_BACKOFF_MAX", 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	lease, err := getDurationEnv("OUTBOX_LEASE", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if maxAttempts <= 0 {
+		return nil, errors.New("OUTBOX_MAX_ATTEMPTS must be positive")
+	}
+	if backoffBase <= 0 || backoffMax < backoffBase {
+		return nil, errors.New("OUTBOX_BACKOFF_BASE must be positive and not greater than OUTBOX_BACKOFF_MAX")
+	}
+	// 1回分の配信中にleaseが切れると他のrelayが重複して配信し、集約内の順序も保証できない
+	if lease <= httpSinkTimeout*time.Duration(batchSize) {
+		return nil, errors.New("OUTBOX_LEASE must be greater than OUTBOX_HTTP_SINK_TIMEOUT * OUTBOX_BATCH_SIZE")
+	}
+
+	var sinks []string
+	for _, name := range strings.Split(getEnv("OUTBOX_SINKS", "log"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			sinks = append(sinks, name)
+		}
+	}
+
+	return &outbox.Config{
+		PollInterval:    pollInterval,
+		BatchSize:       batchSize,
+		MaxAttempts:     maxAttempts,
+		BackoffBase:     backoffBase,
+		BackoffMax:      backoffMax,
+		Lease:           lease,
+		Sinks:           sinks,
+		HTTPSinkURL:     getEnv("OUTBOX_HTTP_SINK_URL", ""),
+		HTTPSinkTimeout: httpSinkTimeout,
+	}, nil
+}
+
+func loadWebhookConfig() (*webhook.Config, error) {
+	pollInterval, err := getDurationEnv("WEBHOOK_POLL_INTERVAL", time.Second)
+	if err != nil {
+		return nil, err
+	}
+	batchSize, err := getIntEnv("WEBHOOK_BATCH_SIZE", 20)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	maxAttempts, err := getIntEnv("WEBHOOK_MAX_ATTEMPTS", 8)
+	if err != nil {
+		return nil, err
+	}
+	backoffBase, err := getDurationEnv("WEBHOOK_BACKOFF_BASE", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	backoffMax, err := getDurationEnv("WEBHOOK_BACKOFF_MAX", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	lease, err := getDurationEnv("WEBHOOK_LEASE", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	allowPrivateNetworks, err := getBoolEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if pollInterval <= 0 || batchSize <= 0 || timeout <= 0 || maxAttempts <= 0 {
+		return nil, errors.New("WEBHOOK_POLL_INTERVAL, WEBHOOK_BATCH_SIZE, WEBHOOK_TIMEOUT and WEBHOOK_MAX_ATTEMPTS must be positive")
+	}
+	if backoffBase <= 0 || backoffMax < backoffBase {
+		return nil, errors.New("WEBHOOK_BACKOFF_BASE must be positive and not greater than WEBHOOK_BACKOFF_MAX")
+	}
+	// 1回分の送信中にleaseが切れると他のdispatcherが重複して送信する
+	if lease <= timeout*time.Duration(batchSize) {
+		return nil, errors.New("WEBHOOK_LEASE must be greater than WEBHOOK_TIMEOUT * WEBHOOK_BATCH_SIZE")
+	}
+
+	return &webhook.Config{
+		PollInterval:         pollInterval,
+		BatchSize:            batchSize,
+		Timeout:              timeout,
+		MaxAttempts:          maxAttempts,
+		BackoffBase:          backoffBase,
+		BackoffMax:           backoffMax,
+		Lease:                lease,
+		AllowPrivateNetworks: allowPrivateNetworks,
+	}, nil
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
 	}
 	return fallback
 }
+
+func getIntEnv(key string, fallback int) (int, error) {
+	if s, exists := os.LookupEnv(key); exists {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value for environment variable %s: %q (expected integer): %w", key, s, err)
+		}
+		return i, nil
+	}
+	return fallback, nil
+}
+
+func getBoolEnv(key string, fallback bool) (bool, error) {
+	if s, exists := os.LookupEnv(key); exists {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return false, fmt.Errorf("invalid value for environment variable %s: %q (expected true or false): %w", key, s, err)
+		}
+		return b, nil
+	}
+	return fallback, nil
+}
+
+func getDurationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	if s, exists := os.LookupEnv(key); exists {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value for environment variable %s: %q (expected duration such as \"5s\"): %w", key, s, err)
+		}
+		return d, nil
+	}
+	return fallback, nil
+}
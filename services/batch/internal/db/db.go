@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// 再試行間隔の上限
+const maxConnectRetryInterval = 30 * time.Second
+
+// Config 接続先はservices/apiと同じDB_*の環境変数から読み込む
+type Config struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	DBName   string
+	SSLMode  string
+
+	// コネクションプール設定。0の場合はdatabase/sqlのデフォルト(無制限)
+	MaxOpenConns int
+	MaxIdleConns int
+
+	// 1回の接続試行のタイムアウト
+	ConnectTimeout time.Duration
+	// クエリ1回あたりの実行時間上限(PostgreSQLのstatement_timeout)。0の場合は無制限
+	StatementTimeout time.Duration
+	// 起動時に接続できない場合の再試行回数と初回の待機時間(再試行ごとに倍増)
+	ConnectRetries       int
+	ConnectRetryInterval time.Duration
+}
+
+// Connect DBへ接続する。接続できない場合はConnectRetries回まで間隔を倍増させながら再試行する
+func Connect(ctx context.Context, config *Config, logger *zap.Logger) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn(config))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+
+	interval := config.ConnectRetryInterval
+	for attempt := 0; ; attempt++ {
+		err = ping(ctx, db, config.ConnectTimeout)
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= config.ConnectRetries {
+			break
+		}
+
+		logger.Warn("failed to ping database, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Int("max_retries", config.ConnectRetries),
+			zap.Duration("retry_in", interval),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(interval):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		interval = min(interval*2, maxConnectRetryInterval)
+	}
+
+	if closeErr := db.Close(); closeErr != nil {
+		logger.Error("failed to close database connection", zap.Error(closeErr))
+	}
+	return nil, fmt.Errorf("failed to ping database: %w", err)
+}
+
+func ping(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return db.PingContext(ctx)
+}
+
+func dsn(config *Config) string {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		config.Host,
+		config.Port,
+		config.User,
+		config.Password,
+		config.DBName,
+		config.SSLMode,
+	)
+	// lib/pqのconnect_timeoutは秒単位
+	if config.ConnectTimeout > 0 {
+		dsn += fmt.Sprintf(" connect_timeout=%d", max(int(config.ConnectTimeout.Seconds()), 1))
+	}
+	// lib/pqは未知のパラメータをrun-time parameterとしてサーバーに渡す
+	if config.StatementTimeout > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", config.StatementTimeout.Milliseconds())
+	}
+	return dsn
+}
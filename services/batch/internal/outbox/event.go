@@ -0,0 +1,28 @@
+// Package outbox services/apiがoutbox_eventsに保存したドメインイベントを外部へ配信する
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event outbox_eventsの1行
+type Event struct {
+	// 挿入順の連番。同じ集約のイベントはこの順に配信する
+	ID int64
+	// 配信先での重複排除用のID
+	EventID       string
+	AggregateType string
+	AggregateID   string
+	EventType     string
+	// ドメインイベントのJSON
+	Payload    json.RawMessage
+	OccurredAt time.Time
+	// これまでに配信に失敗した回数
+	Attempts int
+}
+
+// aggregateKey 配信順を保証する単位
+func (e *Event) aggregateKey() string {
+	return e.AggregateType + ":" + e.AggregateID
+}
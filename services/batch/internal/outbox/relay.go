@@ -0,0 +1,216 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/tokane888/test-mcp/services/batch/internal/webhook"
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	// 配信予定時刻を過ぎたイベントを確認する間隔
+	PollInterval time.Duration
+	// 1回のpollで取得するイベントの上限
+	BatchSize int
+	// 配信の試行回数の上限。上限に達したイベントはdeadとなり、以降配信しない
+	MaxAttempts int
+	// 再配信の間隔。BackoffBaseから失敗ごとに倍増し、BackoffMaxを上限とする
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// 取得したイベントを他のrelayが取得しない期間。HTTPSinkTimeout * BatchSizeより長くすること
+	Lease time.Duration
+	// 配信先(log, http, webhook)
+	Sinks []string
+	// httpの配信先
+	HTTPSinkURL     string
+	HTTPSinkTimeout time.Duration
+}
+
+// Relay outbox_eventsの未配信のイベントを各Sinkへ配信する。
+// 配信に成功した場合のみ配信済みとするため、同じイベントが複数回配信される場合がある(at-least-once)。
+// 同じ集約のイベントは挿入順に配信し、失敗したイベントより後のイベントはそのイベントの配信に成功するまで配信しない。
+// 失敗したイベントは指数バックオフで再配信し、MaxAttempts回失敗したイベントはdeadとして以降のイベントの配信を再開する
+type Relay struct {
+	store  eventStore
+	sinks  []Sink
+	config *Config
+	logger *zap.Logger
+}
+
+// eventStore Storeが満たす。テストでは差し替える
+type eventStore interface {
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]Event, error)
+	MarkPublished(ctx context.Context, id int64, at time.Time) error
+	MarkFailed(ctx context.Context, id int64, cause error, nextAttemptAt time.Time) error
+	MarkDead(ctx context.Context, id int64, cause error, at time.Time) error
+	Release(ctx context.Context, ids []int64) error
+}
+
+func NewRelay(store *Store, sinks []Sink, config *Config, logger *zap.Logger) *Relay {
+	return &Relay{
+		store:  store,
+		sinks:  sinks,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Validate 配信先の設定を検証する。DBへ接続する前に設定誤りを検出するため、NewSinksとは別に行う
+func (c *Config) Validate() error {
+	if len(c.Sinks) == 0 {
+		return errors.New("no outbox sink is configured")
+	}
+	for _, name := range c.Sinks {
+		switch name {
+		case "log", "webhook":
+		case "http":
+			if c.HTTPSinkURL == "" {
+				return errors.New("http sink requires a URL")
+			}
+		default:
+			return fmt.Errorf("unknown outbox sink: %q", name)
+		}
+	}
+	return nil
+}
+
+// NewSinks 設定された配信先を生成する
+func NewSinks(config *Config, db *sql.DB, logger *zap.Logger) ([]Sink, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	sinks := make([]Sink, 0, len(config.Sinks))
+	for _, name := range config.Sinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, NewLogSink(logger))
+		case "http":
+			sinks = append(sinks, NewHTTPSink(config.HTTPSinkURL, config.HTTPSinkTimeout))
+		case "webhook":
+			sinks = append(sinks, NewWebhookSink(webhook.NewStore(db)))
+		default:
+			return nil, fmt.Errorf("unknown outbox sink: %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// Run ctxがキャンセルされるまでPollInterval間隔で配信する
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		published, err := r.RunOnce(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			// DBの一時的な障害等は次回のpollで再試行する
+			r.logger.Error("failed to relay outbox events", zap.Error(err))
+		case published > 0:
+			r.logger.Info("relayed outbox events", zap.Int("count", published))
+		}
+
+		// 取得上限まで配信した場合は残りがあるため待たずに続ける
+		if published >= r.config.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 配信予定時刻を過ぎたイベントを1回分配信し、配信できた件数を返す
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	events, err := r.store.Claim(ctx, r.config.BatchSize, r.config.Lease)
+	if err != nil {
+		return 0, err
+	}
+	if events == nil {
+		r.logger.Debug("no outbox events to relay, or another relay is claiming")
+		return 0, nil
+	}
+
+	published := 0
+	// 配信に失敗したイベントの集約。順序を保つため、同じ集約の以降のイベントは配信せずに解放する
+	blocked := make(map[string]bool)
+	var released []int64
+	for i := range events {
+		event := &events[i]
+		if blocked[event.aggregateKey()] {
+			released = append(released, event.ID)
+			continue
+		}
+
+		if err := r.publish(ctx, event); err != nil {
+			if ctx.Err() != nil {
+				// 停止による失敗は記録せず、lease後に再配信する
+				return published, ctx.Err()
+			}
+			blocked[event.aggregateKey()] = true
+			if err := r.markFailed(ctx, event, err); err != nil {
+				return published, err
+			}
+			continue
+		}
+		if err := r.store.MarkPublished(ctx, event.ID, time.Now()); err != nil {
+			return published, err
+		}
+		published++
+	}
+
+	if err := r.store.Release(ctx, released); err != nil {
+		return published, err
+	}
+	return published, nil
+}
+
+// markFailed 失敗を記録する。MaxAttempts回失敗した場合はdeadとする
+func (r *Relay) markFailed(ctx context.Context, event *Event, cause error) error {
+	attempts := event.Attempts + 1
+	fields := []zap.Field{
+		zap.Int64("id", event.ID),
+		zap.String("event_id", event.EventID),
+		zap.String("event_type", event.EventType),
+		zap.Int("attempts", attempts),
+	}
+	if attempts >= r.config.MaxAttempts {
+		r.logger.Error("outbox event is dead", append(fields, zap.Error(cause))...)
+		return r.store.MarkDead(ctx, event.ID, cause, time.Now())
+	}
+
+	next := time.Now().Add(r.backoff(attempts))
+	r.logger.Warn("failed to publish outbox event, will retry", append(fields, zap.Time("next_attempt_at", next), zap.Error(cause))...)
+	return r.store.MarkFailed(ctx, event.ID, cause, next)
+}
+
+// backoff attempts回目の失敗後の待機時間。複数のイベントの再配信が同時に集中しないよう揺らぎを加える
+func (r *Relay) backoff(attempts int) time.Duration {
+	wait := r.config.BackoffBase
+	for i := 1; i < attempts && wait < r.config.BackoffMax; i++ {
+		wait *= 2
+	}
+	wait = min(wait, r.config.BackoffMax)
+	// [wait/2, wait)の範囲とする
+	half := wait / 2
+	return half + rand.N(wait-half)
+}
+
+// publish 全てのSinkへ配信する。いずれかが失敗した場合、成功したSinkにも再度配信される
+func (r *Relay) publish(ctx context.Context, event *Event) error {
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return fmt.Errorf("sink %s: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type fakeStore struct {
+	events    []Event
+	published []int64
+	failed    map[int64]time.Time
+	dead      []int64
+	released  []int64
+}
+
+func (s *fakeStore) Claim(_ context.Context, limit int, _ time.Duration) ([]Event, error) {
+	return s.events[:min(limit, len(s.events))], nil
+}
+
+func (s *fakeStore) MarkPublished(_ context.Context, id int64, _ time.Time) error {
+	s.published = append(s.published, id)
+	return nil
+}
+
+func (s *fakeStore) MarkFailed(_ context.Context, id int64, _ error, nextAttemptAt time.Time) error {
+	if s.failed == nil {
+		s.failed = make(map[int64]time.Time)
+	}
+	s.failed[id] = nextAttemptAt
+	return nil
+}
+
+func (s *fakeStore) MarkDead(_ context.Context, id int64, _ error, _ time.Time) error {
+	s.dead = append(s.dead, id)
+	return nil
+}
+
+func (s *fakeStore) Release(_ context.Context, ids []int64) error {
+	s.released = append(s.released, ids...)
+	return nil
+}
+
+// failingSink failで指定したIDのイベントの配信に失敗する
+type failingSink struct {
+	fail map[int64]bool
+}
+
+func (s *failingSink) Name() string { return "failing" }
+
+func (s *failingSink) Publish(_ context.Context, event *Event) error {
+	if s.fail[event.ID] {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func newTestRelay(store eventStore, sink Sink) *Relay {
+	return &Relay{
+		store: store,
+		sinks: []Sink{sink},
+		config: &Config{
+			PollInterval: time.Second,
+			BatchSize:    10,
+			MaxAttempts:  3,
+			BackoffBase:  time.Second,
+			BackoffMax:   4 * time.Second,
+			Lease:        time.Minute,
+		},
+		logger: zap.NewNop(),
+	}
+}
+
+func newTestEvent(id int64, aggregateID string, attempts int) Event {
+	return Event{ID: id, EventID: aggregateID + "-event", AggregateType: "user", AggregateID: aggregateID, EventType: "user.created", Attempts: attempts}
+}
+
+// 失敗したイベントの集約の以降のイベントは配信せずに解放し、他の集約のイベントは配信する
+func TestRunOnceBlocksFailedAggregate(t *testing.T) {
+	store := &fakeStore{events: []Event{
+		newTestEvent(1, "a", 0),
+		newTestEvent(2, "b", 0),
+		newTestEvent(3, "a", 0),
+		newTestEvent(4, "b", 0),
+	}}
+	relay := newTestRelay(store, &failingSink{fail: map[int64]bool{1: true}})
+
+	before := time.Now()
+	published, err := relay.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if published != 2 || !slices.Equal(store.published, []int64{2, 4}) {
+		t.Errorf("published = %d %v, want 2 [2 4]", published, store.published)
+	}
+	if !slices.Equal(store.released, []int64{3}) {
+		t.Errorf("released = %v, want [3]", store.released)
+	}
+	next, ok := store.failed[1]
+	if !ok {
+		t.Fatal("event 1 is not marked as failed")
+	}
+	// 1回目の失敗は[BackoffBase/2, BackoffBase)後に再配信する
+	if next.Before(before.Add(500*time.Millisecond)) || next.After(time.Now().Add(time.Second)) {
+		t.Errorf("next attempt at %v is out of the backoff range", next.Sub(before))
+	}
+}
+
+func TestRunOnceMarksDeadAfterMaxAttempts(t *testing.T) {
+	store := &fakeStore{events: []Event{newTestEvent(1, "a", 2)}}
+	relay := newTestRelay(store, &failingSink{fail: map[int64]bool{1: true}})
+
+	if _, err := relay.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if !slices.Equal(store.dead, []int64{1}) {
+		t.Errorf("dead = %v, want [1]", store.dead)
+	}
+	if _, ok := store.failed[1]; ok {
+		t.Error("dead event is scheduled for retry")
+	}
+}
+
+func TestRelayBackoff(t *testing.T) {
+	relay := newTestRelay(nil, nil)
+	tests := []struct {
+		attempts int
+		max      time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		// BackoffMaxを超えない
+		{10, 4 * time.Second},
+	}
+	for _, tt := range tests {
+		got := relay.backoff(tt.attempts)
+		if got < tt.max/2 || got >= tt.max {
+			t.Errorf("backoff(%d) = %v, want [%v, %v)", tt.attempts, got, tt.max/2, tt.max)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/tokane888/test-mcp/services/batch/internal/webhook"
+	"go.uber.org/zap"
+)
+
+// Sink イベントの配信先。同じイベントが複数回渡される場合があるため、配信先ではEventIDで重複を排除すること
+type Sink interface {
+	// Name ログ出力用の名前
+	Name() string
+	// Publish エラーを返した場合、イベントはバックオフ後に再配信される
+	Publish(ctx context.Context, event *Event) error
+}
+
+// envelope 配信先へ送信するJSON
+type envelope struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+func newEnvelope(event *Event) envelope {
+	return envelope{
+		ID:            event.EventID,
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		OccurredAt:    event.OccurredAt,
+		Data:          event.Payload,
+	}
+}
+
+// LogSink イベントをログに出力する。ローカルでの動作確認向け
+type LogSink struct {
+	logger *zap.Logger
+}
+
+func NewLogSink(logger *zap.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Name() string { return "log" }
+
+func (s *LogSink) Publish(_ context.Context, event *Event) error {
+	s.logger.Info("domain event",
+		zap.String("event_id", event.EventID),
+		zap.String("event_type", event.EventType),
+		zap.String("aggregate_type", event.AggregateType),
+		zap.String("aggregate_id", event.AggregateID),
+		zap.Time("occurred_at", event.OccurredAt),
+		// []byteとしてbase64で出力されないよう、JSONのまま出力する
+		zap.Reflect("data", event.Payload),
+	)
+	return nil
+}
+
+// HTTPSink イベントをJSONとしてURLへPOSTする。2xx以外の応答は配信失敗として扱う
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *HTTPSink) Name() string { return "http" }
+
+func (s *HTTPSink) Publish(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(newEnvelope(event))
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// 受信側での重複排除用
+	req.Header.Set("Idempotency-Key", event.EventID)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	// keep-aliveで接続を再利用できるよう読み捨てる
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, s.url)
+	}
+	return nil
+}
+
+// WebhookSink イベントを購読しているwebhookごとに配信を作成する。送信はwebhook-dispatcherが行う
+type WebhookSink struct {
+	store *webhook.Store
+}
+
+func NewWebhookSink(store *webhook.Store) *WebhookSink {
+	return &WebhookSink{store: store}
+}
+
+func (s *WebhookSink) Name() string { return "webhook" }
+
+func (s *WebhookSink) Publish(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(newEnvelope(event))
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if _, err := s.store.Enqueue(ctx, event.EventID, event.EventType, body); err != nil {
+		return err
+	}
+	return nil
+}
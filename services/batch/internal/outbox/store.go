@@ -0,0 +1,153 @@
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// 複数のrelayが同時に取得して順序が入れ替わらないためのadvisory lockのキー(任意の固定値)
+const advisoryLockKey int64 = 6_318_402_977
+
+// last_errorに保存するエラーメッセージの上限
+const maxLastErrorLength = 1000
+
+// Store outbox_eventsの読み書き
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Claim 配信予定時刻を過ぎた未配信のイベントを挿入順に最大limit件取得する。
+// 他のrelayが重複して配信しないよう、取得したイベントの配信予定時刻をlease後に延ばしてcommitする。
+// 同じ集約で先行するイベントが再試行待ち、または他のrelayが配信中の場合は取得しない。
+// 他のrelayが取得中の場合はnilを返す。結果を記録せずに終了した場合はlease後に再配信される
+func (s *Store) Claim(ctx context.Context, limit int, lease time.Duration) (events []Event, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+				pkglogger.FromContext(ctx).Error("failed to rollback transaction", zap.Error(rbErr))
+			}
+		}
+	}()
+
+	// 取得のみを直列化する。lockの取得後に開始したクエリは他のrelayが延ばした配信予定時刻を参照できる
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", advisoryLockKey).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("failed to acquire advisory lock: %w", err)
+	}
+	if !locked {
+		if err := tx.Rollback(); err != nil {
+			return nil, fmt.Errorf("failed to rollback transaction: %w", err)
+		}
+		return nil, nil
+	}
+
+	// deadとなったイベントは以降のイベントの配信を妨げない
+	query := `
+		UPDATE outbox_events
+		SET next_attempt_at = now() + $2 * interval '1 millisecond'
+		WHERE id IN (
+			SELECT e.id
+			FROM outbox_events e
+			WHERE e.published_at IS NULL AND e.dead_at IS NULL AND e.next_attempt_at <= now()
+			  AND NOT EXISTS (
+				SELECT 1
+				FROM outbox_events p
+				WHERE p.aggregate_type = e.aggregate_type AND p.aggregate_id = e.aggregate_id
+				  AND p.id < e.id AND p.published_at IS NULL AND p.dead_at IS NULL
+				  AND p.next_attempt_at > now()
+			  )
+			ORDER BY e.id
+			LIMIT $1
+		)
+		RETURNING id, event_id, aggregate_type, aggregate_id, event_type, payload, occurred_at, attempts`
+	rows, err := tx.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			pkglogger.FromContext(ctx).Error("failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.EventID, &e.AggregateType, &e.AggregateID, &e.EventType, &e.Payload, &e.OccurredAt, &e.Attempts); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// RETURNINGの順序は保証されないため、挿入順に並べ直す
+	slices.SortFunc(events, func(a, b Event) int { return cmp.Compare(a.ID, b.ID) })
+	return events, nil
+}
+
+func (s *Store) MarkPublished(ctx context.Context, id int64, at time.Time) error {
+	if _, err := s.db.ExecContext(ctx, "UPDATE outbox_events SET published_at = $1 WHERE id = $2", at, id); err != nil {
+		return fmt.Errorf("failed to mark outbox event as published: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed 配信の失敗を記録し、nextAttemptAtに再配信する
+func (s *Store) MarkFailed(ctx context.Context, id int64, cause error, nextAttemptAt time.Time) error {
+	query := "UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE id = $1"
+	if _, err := s.db.ExecContext(ctx, query, id, truncateError(cause), nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to mark outbox event as failed: %w", err)
+	}
+	return nil
+}
+
+// MarkDead 配信の失敗を記録し、以降は配信しない
+func (s *Store) MarkDead(ctx context.Context, id int64, cause error, at time.Time) error {
+	query := "UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, dead_at = $3 WHERE id = $1"
+	if _, err := s.db.ExecContext(ctx, query, id, truncateError(cause), at); err != nil {
+		return fmt.Errorf("failed to mark outbox event as dead: %w", err)
+	}
+	return nil
+}
+
+// Release 配信しなかったイベントのleaseを解除し、次回のpollで取得できるようにする
+func (s *Store) Release(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, "UPDATE outbox_events SET next_attempt_at = now() WHERE id = ANY($1)", pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to release outbox events: %w", err)
+	}
+	return nil
+}
+
+func truncateError(err error) string {
+	msg := err.Error()
+	if len(msg) > maxLastErrorLength {
+		// マルチバイト文字の途中で切った場合に不正なUTF-8とならないようにする
+		msg = strings.ToValidUTF8(msg[:maxLastErrorLength], "")
+	}
+	return msg
+}
@@ -0,0 +1,121 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// テスト用DBの接続文字列を指定する環境変数。services/apiのmigrationを適用済みのDBを指定する。
+// outbox_eventsの内容は削除されるため、専用のDBを指定すること
+const testDSNEnv = "TEST_DATABASE_DSN"
+
+const (
+	aggregateA = "00000000-0000-0000-0000-00000000000a"
+	aggregateB = "00000000-0000-0000-0000-00000000000b"
+)
+
+// 再試行待ちのイベントが取得上限を超えて溜まっても、他の集約のイベントは取得できる
+func TestClaimSkipsAggregatesWaitingForRetry(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	store := NewStore(db)
+
+	for i := range 3 {
+		id := insertTestEvent(t, db, fmt.Sprintf("00000000-0000-0000-0000-%012d", 100+i))
+		if err := store.MarkFailed(ctx, id, errors.New("unavailable"), time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("MarkFailed: %v", err)
+		}
+	}
+	want := insertTestEvent(t, db, aggregateA)
+
+	assertClaimed(t, store, 2, want)
+}
+
+func TestClaimKeepsAggregateOrder(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	store := NewStore(db)
+
+	a1 := insertTestEvent(t, db, aggregateA)
+	b1 := insertTestEvent(t, db, aggregateB)
+	a2 := insertTestEvent(t, db, aggregateA)
+	assertClaimed(t, store, 10, a1, b1, a2)
+	// lease中のイベントは他のrelayが取得しない
+	assertClaimed(t, store, 10)
+
+	if err := store.MarkFailed(ctx, a1, errors.New("unavailable"), time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("MarkFailed: %v", err)
+	}
+	if err := store.MarkPublished(ctx, b1, time.Now()); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+	if err := store.Release(ctx, []int64{a2}); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	b2 := insertTestEvent(t, db, aggregateB)
+	// a2はa1の再試行まで取得しない
+	assertClaimed(t, store, 10, b2)
+
+	// deadとなったイベントは以降のイベントの配信を妨げない
+	if err := store.MarkDead(ctx, a1, errors.New("unavailable"), time.Now()); err != nil {
+		t.Fatalf("MarkDead: %v", err)
+	}
+	assertClaimed(t, store, 10, a2)
+}
+
+func assertClaimed(t *testing.T, store *Store, limit int, want ...int64) {
+	t.Helper()
+	events, err := store.Claim(context.Background(), limit, time.Minute)
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	got := make([]int64, len(events))
+	for i, e := range events {
+		got[i] = e.ID
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("claimed = %v, want %v", got, want)
+	}
+}
+
+func insertTestEvent(t *testing.T, db *sql.DB, aggregateID string) int64 {
+	t.Helper()
+	query := `
+		INSERT INTO outbox_events (event_id, aggregate_type, aggregate_id, event_type, payload, occurred_at)
+		VALUES (gen_random_uuid(), 'user', $1, 'user.created', '{}', now())
+		RETURNING id`
+	var id int64
+	if err := db.QueryRowContext(context.Background(), query, aggregateID).Scan(&id); err != nil {
+		t.Fatalf("failed to insert outbox event: %v", err)
+	}
+	return id
+}
+
+// openTestDB TEST_DATABASE_DSNのDBへ接続し、outbox_eventsを空にする。未設定の場合はテストをskipする
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	if _, err := db.ExecContext(context.Background(), "TRUNCATE outbox_events"); err != nil {
+		t.Fatalf("failed to truncate outbox_events: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,182 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Config struct {
+	// 送信予定時刻を過ぎた配信を確認する間隔
+	PollInterval time.Duration
+	// 1回のpollで取得する配信の上限
+	BatchSize int
+	// 送信1回あたりのタイムアウト
+	Timeout time.Duration
+	// 送信回数の上限。上限に達した配信はdeadとなる
+	MaxAttempts int
+	// 再送間隔。BackoffBaseから送信失敗ごとに倍増し、BackoffMaxを上限とする
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// 取得した配信を他のdispatcherが取得しない期間。Timeoutより長くすること
+	Lease time.Duration
+	// trueの場合、ループバック、プライベート等の内部のアドレスへの送信を許可する。ローカルでの確認用
+	AllowPrivateNetworks bool
+}
+
+// Dispatcher 送信予定時刻を過ぎた配信を購読先のURLへPOSTする。
+// 2xx以外の応答、または応答を受け取れなかった場合は指数バックオフで再送し、MaxAttempts回失敗した配信はdeadとする
+type Dispatcher struct {
+	store  *Store
+	client *http.Client
+	config *Config
+	logger *zap.Logger
+}
+
+func NewDispatcher(store *Store, config *Config, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		client: newHTTPClient(config),
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run ctxがキャンセルされるまでPollInterval間隔で送信する
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		claimed, err := d.RunOnce(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			// DBの一時的な障害等は次回のpollで再試行する
+			d.logger.Error("failed to dispatch webhook deliveries", zap.Error(err))
+		case claimed > 0:
+			d.logger.Info("dispatched webhook deliveries", zap.Int("count", claimed))
+		}
+
+		// 取得上限まで送信した場合は残りがあるため待たずに続ける
+		if claimed >= d.config.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 送信予定時刻を過ぎた配信を1回分送信し、送信した件数を返す
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	deliveries, err := d.store.ClaimDue(ctx, d.config.BatchSize, d.config.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		attempt := d.send(ctx, delivery)
+		if ctx.Err() != nil {
+			// 停止による失敗は記録せず、lease後に再送する
+			return i, ctx.Err()
+		}
+
+		attempts := delivery.Attempts + 1
+		status, next := d.nextState(attempt, attempts)
+		if err := d.store.RecordAttempt(ctx, delivery.ID, attempt, status, next); err != nil {
+			return i, err
+		}
+		d.logAttempt(delivery, attempt, attempts, status, next)
+	}
+	return len(deliveries), nil
+}
+
+// send 署名付きのリクエストを1回送信する
+func (d *Dispatcher) send(ctx context.Context, delivery *Delivery) *Attempt {
+	attempt := &Attempt{AttemptedAt: time.Now()}
+	defer func() { attempt.Duration = time.Since(attempt.AttemptedAt) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Err = fmt.Errorf("failed to create request: %w", err)
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "test-mcp-webhook/1.0")
+	// 受信側での重複排除用。再送時も同じ値となる
+	req.Header.Set("Webhook-Id", delivery.EventID)
+	req.Header.Set("Webhook-Event", delivery.EventType)
+	req.Header.Set(SignatureHeader, SignatureHeaderValue(delivery.Secret, attempt.AttemptedAt, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		attempt.Err = fmt.Errorf("failed to send request: %w", err)
+		return attempt
+	}
+	defer func() { _ = resp.Body.Close() }()
+	// keep-aliveで接続を再利用できるよう読み捨てる
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	attempt.StatusCode = &resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attempt.Err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+// nextState 送信結果から配信の状態と次回の送信予定時刻を決める
+func (d *Dispatcher) nextState(attempt *Attempt, attempts int) (Status, time.Time) {
+	switch {
+	case attempt.Err == nil:
+		return StatusSucceeded, time.Time{}
+	case attempts >= d.config.MaxAttempts:
+		return StatusDead, time.Time{}
+	default:
+		return StatusPending, time.Now().Add(d.backoff(attempts))
+	}
+}
+
+// backoff attempts回目の失敗後の待機時間。複数の配信の再送が同時に集中しないよう揺らぎを加える
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.config.BackoffBase
+	for i := 1; i < attempts && wait < d.config.BackoffMax; i++ {
+		wait *= 2
+	}
+	wait = min(wait, d.config.BackoffMax)
+	// [wait/2, wait)の範囲とする
+	half := wait / 2
+	return half + rand.N(wait-half)
+}
+
+func (d *Dispatcher) logAttempt(delivery *Delivery, attempt *Attempt, attempts int, status Status, next time.Time) {
+	fields := []zap.Field{
+		zap.String("delivery_id", delivery.ID),
+		zap.String("event_id", delivery.EventID),
+		zap.String("event_type", delivery.EventType),
+		zap.Int("attempts", attempts),
+		zap.Duration("duration", attempt.Duration),
+	}
+	if attempt.StatusCode != nil {
+		fields = append(fields, zap.Int("status_code", *attempt.StatusCode))
+	}
+
+	switch status {
+	case StatusSucceeded:
+		d.logger.Debug("webhook delivered", fields...)
+	case StatusDead:
+		d.logger.Error("webhook delivery is dead", append(fields, zap.Error(attempt.Err))...)
+	default:
+		d.logger.Warn("webhook delivery failed, will retry", append(fields, zap.Time("next_attempt_at", next), zap.Error(attempt.Err))...)
+	}
+}
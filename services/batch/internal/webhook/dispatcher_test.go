@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func newTestDispatcher(allowPrivateNetworks bool) *Dispatcher {
+	return NewDispatcher(nil, &Config{
+		PollInterval:         time.Second,
+		BatchSize:            1,
+		Timeout:              5 * time.Second,
+		MaxAttempts:          3,
+		BackoffBase:          time.Second,
+		BackoffMax:           4 * time.Second,
+		Lease:                time.Minute,
+		AllowPrivateNetworks: allowPrivateNetworks,
+	}, zap.NewNop())
+}
+
+func newTestDelivery(url string) *Delivery {
+	return &Delivery{
+		ID:        "1",
+		EventID:   "4f0c8a8e-8a9c-4d0e-9f4b-2d8f0f0b6a01",
+		EventType: "user.created",
+		Payload:   []byte(`{"type":"user.created"}`),
+		URL:       url,
+		Secret:    "whsec_test",
+	}
+}
+
+func TestSendSignsRequest(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	delivery := newTestDelivery(server.URL)
+	attempt := newTestDispatcher(true).send(t.Context(), delivery)
+	if attempt.Err != nil {
+		t.Fatalf("attempt.Err = %v", attempt.Err)
+	}
+	if attempt.StatusCode == nil || *attempt.StatusCode != http.StatusNoContent {
+		t.Errorf("attempt.StatusCode = %v, want %d", attempt.StatusCode, http.StatusNoContent)
+	}
+
+	if received.Method != http.MethodPost {
+		t.Errorf("method = %s, want POST", received.Method)
+	}
+	if string(body) != string(delivery.Payload) {
+		t.Errorf("body = %s, want %s", body, delivery.Payload)
+	}
+	if got := received.Header.Get("Webhook-Id"); got != delivery.EventID {
+		t.Errorf("Webhook-Id = %q, want %q", got, delivery.EventID)
+	}
+	if got := received.Header.Get("Webhook-Event"); got != delivery.EventType {
+		t.Errorf("Webhook-Event = %q, want %q", got, delivery.EventType)
+	}
+
+	// 受信側と同じ手順で署名を検証する
+	signature := received.Header.Get(SignatureHeader)
+	ts, v1, ok := strings.Cut(signature, ",")
+	if !ok || !strings.HasPrefix(ts, "t=") || !strings.HasPrefix(v1, "v1=") {
+		t.Fatalf("%s = %q, want t=<unix>,v1=<hex>", SignatureHeader, signature)
+	}
+	unix, err := strconv.ParseInt(strings.TrimPrefix(ts, "t="), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid timestamp in %q: %v", signature, err)
+	}
+	if want := Sign(delivery.Secret, time.Unix(unix, 0), body); strings.TrimPrefix(v1, "v1=") != want {
+		t.Errorf("signature = %q, want v1=%s", v1, want)
+	}
+}
+
+func TestSendNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	attempt := newTestDispatcher(true).send(t.Context(), newTestDelivery(server.URL))
+	if attempt.Err == nil {
+		t.Fatal("attempt.Err = nil, want an error for 500")
+	}
+	if attempt.StatusCode == nil || *attempt.StatusCode != http.StatusInternalServerError {
+		t.Errorf("attempt.StatusCode = %v, want %d", attempt.StatusCode, http.StatusInternalServerError)
+	}
+}
+
+func TestSendDoesNotFollowRedirect(t *testing.T) {
+	var redirected atomic.Bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		redirected.Store(true)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	attempt := newTestDispatcher(true).send(t.Context(), newTestDelivery(server.URL))
+	if attempt.Err == nil {
+		t.Error("attempt.Err = nil, want an error for a redirect")
+	}
+	if redirected.Load() {
+		t.Error("signed request was sent to the redirect target")
+	}
+}
+
+func TestSendRejectsPrivateAddress(t *testing.T) {
+	var called atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called.Store(true)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// httptestのserverはループバックのアドレスで待ち受ける
+	attempt := newTestDispatcher(false).send(t.Context(), newTestDelivery(server.URL))
+	if !errors.Is(attempt.Err, ErrNonPublicAddress) {
+		t.Errorf("attempt.Err = %v, want %v", attempt.Err, ErrNonPublicAddress)
+	}
+	if attempt.StatusCode != nil {
+		t.Errorf("attempt.StatusCode = %d, want nil", *attempt.StatusCode)
+	}
+	if called.Load() {
+		t.Error("request was sent to a loopback address")
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"203.0.113.10", true},
+		{"2001:4860:4860::8888", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"10.0.0.5", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"::ffff:10.0.0.5", false},
+		{"0.0.0.0", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("isPublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestNextState(t *testing.T) {
+	d := newTestDispatcher(true)
+	failed := &Attempt{Err: errors.New("unexpected status code 500")}
+
+	if status, _ := d.nextState(&Attempt{}, 1); status != StatusSucceeded {
+		t.Errorf("status after success = %s, want %s", status, StatusSucceeded)
+	}
+	status, next := d.nextState(failed, 1)
+	if status != StatusPending {
+		t.Errorf("status after 1 failure = %s, want %s", status, StatusPending)
+	}
+	if wait := time.Until(next); wait <= 0 || wait > d.config.BackoffBase {
+		t.Errorf("next attempt in %s, want within %s", wait, d.config.BackoffBase)
+	}
+	if status, _ := d.nextState(failed, d.config.MaxAttempts); status != StatusDead {
+		t.Errorf("status after %d failures = %s, want %s", d.config.MaxAttempts, status, StatusDead)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := newTestDispatcher(true)
+	tests := []struct {
+		attempts int
+		max      time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		// BackoffMaxを上限とする
+		{10, 4 * time.Second},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			if got := d.backoff(tt.attempts); got < tt.max/2 || got >= tt.max {
+				t.Errorf("backoff(%d) = %s, want in [%s, %s)", tt.attempts, got, tt.max/2, tt.max)
+			}
+		}
+	}
+}
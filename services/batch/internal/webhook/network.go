@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrNonPublicAddress 送信先が内部のアドレスに解決された場合のエラー
+var ErrNonPublicAddress = errors.New("webhook destination resolves to a non-public address")
+
+// nonPublicPrefixes netipで判定できない、インターネットから到達できないアドレスの範囲
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// isPublicAddr ループバック、リンクローカル、プライベート等のアドレスではない場合true
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDialAddress 名前解決後の接続先を検証する。
+// APIでの登録時の検証後にDNSの応答が変わった場合も、内部のサービスへ送信しない(SSRF対策)
+func checkDialAddress(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, address)
+	}
+	if !isPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+func newHTTPClient(config *Config) *http.Client {
+	dialer := &net.Dialer{Timeout: config.Timeout, KeepAlive: 30 * time.Second}
+	if !config.AllowPrivateNetworks {
+		dialer.Control = checkDialAddress
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// proxyを経由すると接続先のアドレスを検証できないため、環境変数のproxyは使用しない
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   config.Timeout,
+		Transport: transport,
+		// リダイレクト先へ署名付きのリクエストを送信しない
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
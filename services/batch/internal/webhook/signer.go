@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// SignatureHeader 署名を格納するヘッダー。値は"t=<unix秒>,v1=<署名>"
+const SignatureHeader = "Webhook-Signature"
+
+// Sign "<unix秒>.<リクエストボディ>"のHMAC-SHA256をhexで返す。
+// 受信側は同じ値を計算して比較し、タイムスタンプが許容範囲内であることを確認する(リプレイ攻撃対策)
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeaderValue SignatureHeaderの値を返す
+func SignatureHeaderValue(secret string, timestamp time.Time, body []byte) string {
+	return "t=" + strconv.FormatInt(timestamp.Unix(), 10) + ",v1=" + Sign(secret, timestamp, body)
+}
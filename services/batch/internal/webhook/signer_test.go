@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"a":1}`)
+
+	// 受信側の実装と照合できるよう、HMAC-SHA256(secret, "1700000000.{"a":1}")を固定値で確認する
+	const want = "38877139021993b830af32feea6e18a8da83eb2f6e49ee50bd9e4cf4ca4d3789"
+	if got := Sign("whsec_test", timestamp, body); got != want {
+		t.Errorf("Sign = %q, want %q", got, want)
+	}
+	if got := SignatureHeaderValue("whsec_test", timestamp, body); got != "t=1700000000,v1="+want {
+		t.Errorf("SignatureHeaderValue = %q", got)
+	}
+}
+
+func TestSignDependsOnInputs(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"a":1}`)
+	base := Sign("whsec_test", timestamp, body)
+
+	if Sign("whsec_other", timestamp, body) == base {
+		t.Error("signature does not depend on the secret")
+	}
+	if Sign("whsec_test", timestamp.Add(time.Second), body) == base {
+		t.Error("signature does not depend on the timestamp")
+	}
+	if Sign("whsec_test", timestamp, []byte(`{"a":2}`)) == base {
+		t.Error("signature does not depend on the body")
+	}
+}
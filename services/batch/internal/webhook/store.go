@@ -0,0 +1,180 @@
+// Package webhook ドメインイベントをwebhookの購読先へ署名付きで送信する
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	pkglogger "github.com/tokane888/test-mcp/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// last_error、attemptのerrorに保存するエラーメッセージの上限
+const maxErrorLength = 1000
+
+// Status webhook_deliveries.status
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusSucceeded Status = "succeeded"
+	StatusDead      Status = "dead"
+)
+
+// Delivery 送信対象の配信。送信先の購読の情報を含む
+type Delivery struct {
+	ID        string
+	EventID   string
+	EventType string
+	// 送信するリクエストボディ
+	Payload []byte
+	// これまでの送信回数
+	Attempts int
+	URL      string
+	Secret   string
+}
+
+// Attempt 送信1回分の結果
+type Attempt struct {
+	AttemptedAt time.Time
+	// 応答を受け取れなかった場合はnil
+	StatusCode *int
+	// 2xxの応答の場合はnil
+	Err      error
+	Duration time.Duration
+}
+
+// Store webhook_subscriptions、webhook_deliveriesの読み書き
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Enqueue イベント種別を購読している有効な購読ごとに配信を作成し、作成した件数を返す。
+// 同じイベントの配信が既に存在する購読には作成しない
+func (s *Store) Enqueue(ctx context.Context, eventID, eventType string, payload []byte) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, next_attempt_at)
+		SELECT id, $1, $2, $3, 'pending', now()
+		FROM webhook_subscriptions
+		WHERE active AND $2 = ANY(event_types)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`
+	result, err := s.db.ExecContext(ctx, query, eventID, eventType, payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert webhook deliveries: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return n, nil
+}
+
+// ClaimDue 送信予定時刻を過ぎた配信を最大limit件取得する。
+// 他のdispatcherが重複して送信しないよう、取得した配信の送信予定時刻をlease後に延ばす。
+// 結果を記録せずに終了した場合はlease後に再送される
+func (s *Store) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error) {
+	// 無効な購読の配信は有効に戻されるまで送信しない
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + $2 * interval '1 millisecond', updated_at = now()
+		FROM webhook_subscriptions s
+		WHERE d.subscription_id = s.id
+		  AND d.id IN (
+			SELECT d2.id
+			FROM webhook_deliveries d2
+			JOIN webhook_subscriptions s2 ON s2.id = d2.subscription_id
+			WHERE d2.status = 'pending' AND d2.next_attempt_at <= now() AND s2.active
+			ORDER BY d2.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d2 SKIP LOCKED
+		  )
+		RETURNING d.id, d.event_id, d.event_type, d.payload, d.attempts, s.url, s.secret`
+	rows, err := s.db.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			pkglogger.FromContext(ctx).Error("failed to close rows", zap.Error(closeErr))
+		}
+	}()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		var d Delivery
+		if err := rows.Scan(&d.ID, &d.EventID, &d.EventType, &d.Payload, &d.Attempts, &d.URL, &d.Secret); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RecordAttempt 送信結果を記録し、配信の状態を更新する。
+// nextAttemptAtはstatusがpendingの場合のみ使用する
+func (s *Store) RecordAttempt(ctx context.Context, deliveryID string, attempt *Attempt, status Status, nextAttemptAt time.Time) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				pkglogger.FromContext(ctx).Error("failed to rollback transaction", zap.Error(rbErr))
+			}
+		}
+	}()
+
+	var errorMessage sql.NullString
+	if attempt.Err != nil {
+		errorMessage = sql.NullString{String: truncateError(attempt.Err), Valid: true}
+	}
+	var statusCode sql.NullInt64
+	if attempt.StatusCode != nil {
+		statusCode = sql.NullInt64{Int64: int64(*attempt.StatusCode), Valid: true}
+	}
+
+	insertQuery := `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempted_at, status_code, error, duration_ms)
+		VALUES ($1, $2, $3, $4, $5)`
+	if _, err := tx.ExecContext(ctx, insertQuery, deliveryID, attempt.AttemptedAt, statusCode, errorMessage, attempt.Duration.Milliseconds()); err != nil {
+		return fmt.Errorf("failed to insert webhook delivery attempt: %w", err)
+	}
+
+	var next sql.NullTime
+	if status == StatusPending {
+		next = sql.NullTime{Time: nextAttemptAt, Valid: true}
+	}
+	updateQuery := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, next_attempt_at = $3,
+			last_attempt_at = $4, last_status_code = $5, last_error = $6, updated_at = now()
+		WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, updateQuery, deliveryID, string(status), next, attempt.AttemptedAt, statusCode, errorMessage); err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func truncateError(err error) string {
+	message := err.Error()
+	if len(message) > maxErrorLength {
+		// マルチバイト文字の途中で切った場合に不正なUTF-8とならないよう除去する
+		message = strings.ToValidUTF8(message[:maxErrorLength], "")
+	}
+	return message
+}